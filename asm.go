@@ -0,0 +1,175 @@
+package environ
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+const (
+	// separates the secret id from the json key to extract from the secret in asm tags
+	asmKeySeparator = "#"
+	// separates the secret id from the version selection in asm tags
+	asmVersionSeparator = "?"
+	// version selection params for asm tags
+	asmVersionStageParam = "version_stage"
+	asmVersionIDParam    = "version_id"
+)
+
+// errSecretNotJSON is returned when a key is extracted from a secret that is not a JSON object
+var errSecretNotJSON = errors.New("secret is not a JSON object")
+
+// ASMClient is the subset of the AWS Secrets Manager client used to read secrets
+type ASMClient interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// ASMSource reads values from AWS Secrets Manager by `asm` tags. A tag can be a secret id, to load the whole secret,
+// or a secret id and a key, IE: `asm:"prod/mysql#password"`, to extract a single key from a secret stored as a JSON
+// object. A version can be selected with a version_stage or version_id param, IE:
+// `asm:"prod/mysql?version_stage=AWSPREVIOUS#password"`. Each secret version is fetched once and shared by every field
+// that references it.
+type ASMSource struct {
+	client ASMClient
+
+	mu      sync.Mutex
+	secrets map[asmSecretRef]*string
+}
+
+// asmSecretRef identifies a single version of a secret
+type asmSecretRef struct {
+	id           string
+	versionStage string
+	versionID    string
+}
+
+// NewASMSource creates an ASMSource from an AWS config. The endpoint overrides the default AWS endpoint when it is not
+// empty, IE: http://localhost:4566 for LocalStack.
+func NewASMSource(cfg aws.Config, endpoint string) *ASMSource {
+	client := secretsmanager.NewFromConfig(cfg, func(o *secretsmanager.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+	return NewASMSourceFromClient(client)
+}
+
+// NewASMSourceFromClient creates an ASMSource using an existing client
+func NewASMSourceFromClient(client ASMClient) *ASMSource {
+	return &ASMSource{
+		client:  client,
+		secrets: map[asmSecretRef]*string{},
+	}
+}
+
+// Tag returns the asm tag
+func (s *ASMSource) Tag() string {
+	return asmTag
+}
+
+// Preload clears any previously fetched secrets and fetches each secret referenced by the keys once
+func (s *ASMSource) Preload(keys []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secrets = map[asmSecretRef]*string{}
+	for _, key := range keys {
+		ref, _, err := parseASMKey(key)
+		if err != nil {
+			return err
+		}
+		_, err = s.secret(ref)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lookup returns the secret, or the key extracted from the secret, referenced by key
+func (s *ASMSource) Lookup(key string) (string, bool, error) {
+	ref, jsonKey, err := parseASMKey(key)
+	if err != nil {
+		return "", false, err
+	}
+	s.mu.Lock()
+	secret, err := s.secret(ref)
+	s.mu.Unlock()
+	if err != nil || secret == nil {
+		return "", false, err
+	}
+	if jsonKey == "" {
+		return *secret, true, nil
+	}
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal([]byte(*secret), &fields)
+	if err != nil {
+		return "", false, errSecretNotJSON
+	}
+	raw, ok := fields[jsonKey]
+	if !ok {
+		return "", false, nil
+	}
+	// strings are unquoted, any other JSON value is used as is
+	var v string
+	if json.Unmarshal(raw, &v) != nil {
+		v = string(raw)
+	}
+	return v, true, nil
+}
+
+// returns the cached secret for the ref, fetching it when it has not been fetched yet. Secrets that do not exist are
+// cached as nil.
+func (s *ASMSource) secret(ref asmSecretRef) (*string, error) {
+	if secret, ok := s.secrets[ref]; ok {
+		return secret, nil
+	}
+	input := &secretsmanager.GetSecretValueInput{SecretId: aws.String(ref.id)}
+	if ref.versionStage != "" {
+		input.VersionStage = aws.String(ref.versionStage)
+	}
+	if ref.versionID != "" {
+		input.VersionId = aws.String(ref.versionID)
+	}
+	output, err := s.client.GetSecretValue(context.Background(), input)
+	var notFound *types.ResourceNotFoundException
+	if errors.As(err, &notFound) {
+		s.secrets[ref] = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	secret := aws.ToString(output.SecretString)
+	if output.SecretString == nil {
+		secret = string(output.SecretBinary)
+	}
+	s.secrets[ref] = &secret
+	return &secret, nil
+}
+
+// splits an asm tag into the secret version it references and the json key to extract
+func parseASMKey(key string) (asmSecretRef, string, error) {
+	var (
+		ref     asmSecretRef
+		jsonKey string
+	)
+	key, jsonKey, _ = strings.Cut(key, asmKeySeparator)
+	id, rawQuery, found := strings.Cut(key, asmVersionSeparator)
+	ref.id = id
+	if found {
+		params, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			return ref, jsonKey, err
+		}
+		ref.versionStage = params.Get(asmVersionStageParam)
+		ref.versionID = params.Get(asmVersionIDParam)
+	}
+	return ref, jsonKey, nil
+}
@@ -0,0 +1,151 @@
+package environ_test
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/NeedMoreVolume/environ"
+)
+
+// fakeASM mimics the GetSecretValue action of the Secrets Manager JSON API
+type fakeASM struct {
+	mu sync.Mutex
+	// secrets by id then version stage
+	secrets map[string]map[string]string
+	calls   int
+}
+
+func (f *fakeASM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" {
+		http.Error(w, "unsupported action", http.StatusBadRequest)
+		return
+	}
+	var input struct {
+		SecretID     string `json:"SecretId"`
+		VersionStage string
+		VersionID    string `json:"VersionId"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	stage := input.VersionStage
+	if input.VersionID != "" {
+		stage = input.VersionID
+	}
+	if stage == "" {
+		stage = "AWSCURRENT"
+	}
+	secret, ok := f.secrets[input.SecretID][stage]
+	if !ok {
+		w.Header().Set("X-Amzn-ErrorType", "ResourceNotFoundException")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`))
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"Name":          input.SecretID,
+		"SecretString":  secret,
+		"VersionStages": []string{stage},
+	})
+}
+
+type exampleASMConfig struct {
+	Username    string            `asm:"prod/mysql#username" required:"true"`
+	Password    string            `asm:"prod/mysql#password" required:"true"`
+	Port        int               `asm:"prod/mysql#port" default:"3306"`
+	Options     map[string]string `asm:"prod/mysql#options"`
+	OldPassword string            `asm:"prod/mysql?version_stage=AWSPREVIOUS#password"`
+	PinnedKey   string            `asm:"prod/mysql?version_id=v1#password"`
+	Token       string            `asm:"prod/token"`
+	Missing     string            `asm:"prod/missing#key" default:"fallback"`
+	MissingKey  string            `asm:"prod/mysql#missing" default:"fallback"`
+}
+
+func TestASMSource(t *testing.T) {
+	fake := &fakeASM{
+		secrets: map[string]map[string]string{
+			"prod/mysql": {
+				"AWSCURRENT":  `{"username":"admin","password":"hunter2","port":3307,"options":"charset:utf8"}`,
+				"AWSPREVIOUS": `{"username":"admin","password":"hunter1"}`,
+				"v1":          `{"username":"admin","password":"hunter0"}`,
+			},
+			"prod/token": {
+				"AWSCURRENT": "plain text token",
+			},
+		},
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	var cfg exampleASMConfig
+	err := environ.LoadWith(&cfg, environ.NewASMSource(testAWSConfig(), server.URL))
+	if err != nil {
+		slog.Error("failed to load asm config", "error", err)
+		t.FailNow()
+	}
+	expected := exampleASMConfig{
+		Username:    "admin",
+		Password:    "hunter2",
+		Port:        3307,
+		Options:     map[string]string{"charset": "utf8"},
+		OldPassword: "hunter1",
+		PinnedKey:   "hunter0",
+		Token:       "plain text token",
+		Missing:     "fallback",
+		MissingKey:  "fallback",
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		slog.Error("expected result does not match result", "expected result", expected, "result", cfg)
+		t.Fail()
+	}
+	// each secret version should only be fetched once
+	if fake.calls != 5 {
+		slog.Error("secrets were fetched more than once", "calls", fake.calls)
+		t.Fail()
+	}
+}
+
+func TestASMSourceErrors(t *testing.T) {
+	fake := &fakeASM{
+		secrets: map[string]map[string]string{
+			"prod/token": {
+				"AWSCURRENT": "plain text token",
+			},
+		},
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	source := environ.NewASMSource(testAWSConfig(), server.URL)
+	_, _, err := source.Lookup("prod/token#key")
+	if err == nil {
+		slog.Error("expected an error extracting a key from a plain text secret")
+		t.Fail()
+	}
+
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "unavailable", http.StatusBadRequest)
+	}))
+	defer unavailable.Close()
+
+	var cfg exampleASMConfig
+	err = environ.LoadWith(&cfg, environ.NewASMSource(testAWSConfig(), unavailable.URL))
+	var envErr *environ.EnvError
+	if !errors.As(err, &envErr) || envErr.Err != environ.ErrLoading || envErr.Key != "asm" {
+		slog.Error("expected a loading error", "error", err)
+		t.Fail()
+	}
+}
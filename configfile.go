@@ -0,0 +1,313 @@
+package environ
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// config file formats
+const (
+	JSON ConfigFormat = "json"
+	YAML ConfigFormat = "yaml"
+	TOML ConfigFormat = "toml"
+)
+
+// configKeySeparator separates the segments of a key path in config tags, IE: database.host
+const configKeySeparator = "."
+
+var (
+	// errUnknownConfigFormat is returned for config files in a format that is not supported
+	errUnknownConfigFormat = errors.New("unknown config file format")
+	// errConfigNotObject is returned for config files that are not an object at the top level
+	errConfigNotObject = errors.New("config file must contain an object")
+	// errConfigNotCollection is returned for values loaded into slices and maps of structs that are not an array or
+	// object of objects
+	errConfigNotCollection = errors.New("config file value must be an array or object of objects")
+)
+
+// ConfigFormat is the format of a config file
+type ConfigFormat string
+
+// ConfigFileSource reads values from a JSON, YAML or TOML config file by `config` tags, where the tag is the path of
+// keys leading to a value separated by dots, IE: `config:"database.host"`. Fields without a config tag are looked up by
+// the path of field names leading to them, IE: Database.Host. Keys are matched case insensitively, and elements of
+// arrays can be selected by index, IE: `config:"servers.0.host"`.
+//
+// Arrays and objects are loaded into slices and maps item by item, so items can contain separators. Arrays of objects
+// and objects of objects are loaded into slices and maps of structs, where the keys of the fields of each element are
+// relative to the element, IE: `config:"host"` for the host of each of the servers in `config:"servers"`.
+//
+// The file is read at the start of every load.
+type ConfigFileSource struct {
+	path   string
+	format ConfigFormat
+
+	mu  sync.Mutex
+	doc map[string]any
+}
+
+// NewConfigFileSource creates a ConfigFileSource for a file, using the extension of the file to pick its format
+func NewConfigFileSource(path string) (*ConfigFileSource, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return NewJSONFileSource(path)
+	case ".yaml", ".yml":
+		return NewYAMLFileSource(path)
+	case ".toml":
+		return NewTOMLFileSource(path)
+	}
+	return nil, errUnknownConfigFormat
+}
+
+// NewJSONFileSource creates a ConfigFileSource for a JSON file
+func NewJSONFileSource(path string) (*ConfigFileSource, error) {
+	return newConfigFileSource(path, JSON)
+}
+
+// NewYAMLFileSource creates a ConfigFileSource for a YAML file
+func NewYAMLFileSource(path string) (*ConfigFileSource, error) {
+	return newConfigFileSource(path, YAML)
+}
+
+// NewTOMLFileSource creates a ConfigFileSource for a TOML file
+func NewTOMLFileSource(path string) (*ConfigFileSource, error) {
+	return newConfigFileSource(path, TOML)
+}
+
+// creates a ConfigFileSource and reads the file, so problems with the file are found before loading
+func newConfigFileSource(path string, format ConfigFormat) (*ConfigFileSource, error) {
+	s := &ConfigFileSource{
+		path:   path,
+		format: format,
+	}
+	err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Tag returns the config tag
+func (s *ConfigFileSource) Tag() string {
+	return configTag
+}
+
+// DeriveKey joins the names of the fields leading to a field with dots
+func (s *ConfigFileSource) DeriveKey(path []string) string {
+	return strings.Join(path, configKeySeparator)
+}
+
+// Preload reads the file again, so changes to the file are picked up on every load
+func (s *ConfigFileSource) Preload(_ []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.read()
+}
+
+// Lookup returns the value at the key path
+func (s *ConfigFileSource) Lookup(key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	node := s.node(key)
+	if node == nil {
+		return "", false, nil
+	}
+	return configValue(node, false), true, nil
+}
+
+// returns the parsed value at the key path
+func (s *ConfigFileSource) lookupNode(key string) any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.node(key)
+}
+
+// returns the indices of the array or the keys of the object at the key path, with a source for each element that
+// looks up keys relative to the element
+func (s *ConfigFileSource) elements(key string) ([]string, map[string]Source, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var (
+		names   []string
+		objects = map[string]any{}
+	)
+	switch n := s.node(key).(type) {
+	case nil:
+		return nil, nil, nil
+	case []any:
+		for i, v := range n {
+			name := strconv.Itoa(i)
+			names = append(names, name)
+			objects[name] = v
+		}
+	case map[string]any:
+		for k, v := range n {
+			names = append(names, k)
+			objects[k] = v
+		}
+		sort.Strings(names)
+	default:
+		return nil, nil, errConfigNotCollection
+	}
+	sources := make(map[string]Source, len(names))
+	for _, name := range names {
+		obj, ok := objects[name].(map[string]any)
+		if !ok {
+			return nil, nil, errConfigNotCollection
+		}
+		sources[name] = &ConfigFileSource{path: s.path, format: s.format, doc: obj}
+	}
+	return names, sources, nil
+}
+
+// returns the value at the key path, or nil when there is none
+func (s *ConfigFileSource) node(key string) any {
+	var node any = s.doc
+	for _, segment := range strings.Split(key, configKeySeparator) {
+		switch n := node.(type) {
+		case map[string]any:
+			node = lookupConfigKey(n, segment)
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil
+			}
+			node = n[i]
+		default:
+			return nil
+		}
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// reads and parses the file
+func (s *ConfigFileSource) read() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+	var doc any
+	switch s.format {
+	case JSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		// keep numbers as they are written so large integers are not rounded
+		decoder.UseNumber()
+		err = decoder.Decode(&doc)
+	case YAML:
+		var node yaml.Node
+		err = yaml.Unmarshal(data, &node)
+		doc = yamlValue(&node)
+	case TOML:
+		err = toml.Unmarshal(data, &doc)
+	default:
+		err = errUnknownConfigFormat
+	}
+	if err != nil {
+		return err
+	}
+	// an empty yaml document is a nil object
+	if doc == nil {
+		doc = map[string]any{}
+	}
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return errConfigNotObject
+	}
+	s.doc = obj
+	return nil
+}
+
+// converts a yaml node to the values used for the other formats, keeping scalars as they are written so large
+// numbers are not rounded
+func yamlValue(node *yaml.Node) any {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return yamlValue(node.Content[0])
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.SequenceNode:
+		values := make([]any, 0, len(node.Content))
+		for _, item := range node.Content {
+			values = append(values, yamlValue(item))
+		}
+		return values
+	case yaml.MappingNode:
+		obj := make(map[string]any, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			obj[node.Content[i].Value] = yamlValue(node.Content[i+1])
+		}
+		return obj
+	case yaml.ScalarNode:
+		if node.ShortTag() == "!!null" {
+			return nil
+		}
+		return node.Value
+	}
+	return nil
+}
+
+// returns the value of a key in an object, preferring an exact match over a case insensitive one
+func lookupConfigKey(obj map[string]any, key string) any {
+	if v, ok := obj[key]; ok {
+		return v
+	}
+	for k, v := range obj {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return nil
+}
+
+// converts a value from a config file to a string, arrays and objects use the default separators for slices and maps
+// and are wrapped in brackets when they are nested
+func configValue(node any, nested bool) string {
+	var items []string
+	switch n := node.(type) {
+	case nil:
+		return ""
+	case string:
+		return n
+	case time.Time:
+		return n.Format(time.RFC3339Nano)
+	case []any:
+		for _, v := range n {
+			items = append(items, configValue(v, true))
+		}
+	case map[string]any:
+		for k, v := range n {
+			items = append(items, k+defaultKvSeparator+configValue(v, true))
+		}
+		sort.Strings(items)
+	case map[any]any:
+		for k, v := range n {
+			items = append(items, fmt.Sprint(k)+defaultKvSeparator+configValue(v, true))
+		}
+		sort.Strings(items)
+	default:
+		return fmt.Sprint(n)
+	}
+	value := strings.Join(items, defaultSeparator)
+	if nested {
+		return string(openBracket) + value + string(closeBracket)
+	}
+	return value
+}
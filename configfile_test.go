@@ -0,0 +1,274 @@
+package environ_test
+
+import (
+	"errors"
+	"log/slog"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NeedMoreVolume/environ"
+)
+
+const (
+	exampleJSONConfig = `{
+	"name": "service",
+	"database": {"host": "db.internal", "port": 3307, "options": {"charset": "utf8"}},
+	"servers": [{"host": "a.internal"}, {"host": "b.internal"}],
+	"tags": ["a", "b"],
+	"matrix": [[1, 2], [3]],
+	"timeout": "5s",
+	"big": 123456789012345678901234567890,
+	"Cache": {"TTL": "1m"},
+	"regions": {"eu": {"host": "eu.internal", "port": 8443}},
+	"upstreams": {"api": "10.0.0.1:8080"},
+	"commas": ["a,b", "c"],
+	"semicolons": ["x,y", "z"],
+	"enabled": true
+}`
+	exampleYAMLConfig = `
+name: service
+database:
+  host: db.internal
+  port: 3307
+  options:
+    charset: utf8
+servers:
+  - host: a.internal
+  - host: b.internal
+tags: [a, b]
+matrix: [[1, 2], [3]]
+timeout: 5s
+big: 123456789012345678901234567890
+cache:
+  ttl: 1m
+regions:
+  eu:
+    host: eu.internal
+    port: 8443
+upstreams:
+  api: "10.0.0.1:8080"
+commas: ["a,b", c]
+semicolons: ["x,y", z]
+enabled: true
+`
+	exampleTOMLConfig = `
+name = "service"
+tags = ["a", "b"]
+matrix = [[1, 2], [3]]
+timeout = "5s"
+big = "123456789012345678901234567890"
+enabled = true
+servers = [{ host = "a.internal" }, { host = "b.internal" }]
+commas = ["a,b", "c"]
+semicolons = ["x,y", "z"]
+
+[database]
+host = "db.internal"
+port = 3307
+
+[database.options]
+charset = "utf8"
+
+[cache]
+ttl = "1m"
+
+[regions.eu]
+host = "eu.internal"
+port = 8443
+
+[upstreams]
+api = "10.0.0.1:8080"
+`
+)
+
+type exampleConfigServer struct {
+	Host string `env:"HOST" config:"host"`
+	Port int    `env:"PORT" config:"port" default:"80"`
+}
+
+type exampleConfigFileConfig struct {
+	Name        string            `env:"MY_NAME" config:"name"`
+	Host        string            `env:"MY_HOST" config:"database.host"`
+	Port        int               `config:"database.port"`
+	Options     map[string]string `config:"database.options"`
+	FirstServer string            `config:"servers.0.host"`
+	Tags        []string          `config:"tags"`
+	Matrix      [][]int           `config:"matrix"`
+	Timeout     time.Duration     `config:"timeout"`
+	Big         *big.Int          `config:"big"`
+	Enabled     bool              `config:"enabled"`
+	Missing     string            `config:"database.missing" default:"fallback"`
+	Cache       struct {
+		TTL time.Duration
+	}
+	Servers []exampleConfigServer `env:"MY_SERVERS" config:"servers"`
+	Regions map[string]exampleConfigServer
+	// items of arrays and objects are not split by separators
+	Upstreams  map[string]string `config:"upstreams"`
+	Commas     []string          `config:"commas"`
+	Semicolons *[]string         `config:"semicolons" separator:";"`
+}
+
+func writeConfigFile(t *testing.T, name, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, []byte(contents), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfigFileSource(t *testing.T) {
+	expectedBig, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	testCases := map[string]struct {
+		name     string
+		contents string
+	}{
+		"json": {name: "config.json", contents: exampleJSONConfig},
+		"yaml": {name: "config.yaml", contents: exampleYAMLConfig},
+		"yml":  {name: "config.yml", contents: exampleYAMLConfig},
+		"toml": {name: "config.toml", contents: exampleTOMLConfig},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("MY_HOST", "env.internal")
+			t.Setenv("MY_SERVERS_1_PORT", "9090")
+			source, err := environ.NewConfigFileSource(writeConfigFile(t, tc.name, tc.contents))
+			if err != nil {
+				slog.Error("failed to read config file", "error", err)
+				t.FailNow()
+			}
+			var cfg exampleConfigFileConfig
+			err = environ.Load(&cfg, environ.WithSources(environ.EnvSource(), source))
+			if err != nil {
+				slog.Error("failed to load config", "error", err)
+				t.FailNow()
+			}
+			expected := exampleConfigFileConfig{
+				Name:        "service",
+				Host:        "env.internal",
+				Port:        3307,
+				Options:     map[string]string{"charset": "utf8"},
+				FirstServer: "a.internal",
+				Tags:        []string{"a", "b"},
+				Matrix:      [][]int{{1, 2}, {3}},
+				Timeout:     5 * time.Second,
+				Big:         expectedBig,
+				Enabled:     true,
+				Missing:     "fallback",
+			}
+			expected.Cache.TTL = time.Minute
+			// elements of slices and maps of structs are loaded from every source that has them
+			expected.Servers = []exampleConfigServer{
+				{Host: "a.internal", Port: 80},
+				{Host: "b.internal", Port: 9090},
+			}
+			expected.Regions = map[string]exampleConfigServer{
+				"eu": {Host: "eu.internal", Port: 8443},
+			}
+			expected.Upstreams = map[string]string{"api": "10.0.0.1:8080"}
+			expected.Commas = []string{"a,b", "c"}
+			expected.Semicolons = &[]string{"x,y", "z"}
+			if !reflect.DeepEqual(cfg, expected) {
+				slog.Error("expected result does not match result", "expected result", expected, "result", cfg)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestConfigFileSourceReload(t *testing.T) {
+	path := writeConfigFile(t, "config.json", `{"name": "before"}`)
+	source, err := environ.NewJSONFileSource(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte(`{"name": "after"}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cfg exampleConfigFileConfig
+	err = environ.LoadWith(&cfg, source)
+	if err != nil || cfg.Name != "after" {
+		slog.Error("config file was not read again on load", "name", cfg.Name, "error", err)
+		t.Fail()
+	}
+}
+
+func TestConfigFileSourceErrors(t *testing.T) {
+	testCases := map[string]struct {
+		create func(t *testing.T) (*environ.ConfigFileSource, error)
+	}{
+		"unknown extension": {
+			create: func(t *testing.T) (*environ.ConfigFileSource, error) {
+				return environ.NewConfigFileSource(writeConfigFile(t, "config.ini", "name=service"))
+			},
+		},
+		"missing file": {
+			create: func(t *testing.T) (*environ.ConfigFileSource, error) {
+				return environ.NewJSONFileSource(filepath.Join(t.TempDir(), "missing.json"))
+			},
+		},
+		"invalid json": {
+			create: func(t *testing.T) (*environ.ConfigFileSource, error) {
+				return environ.NewJSONFileSource(writeConfigFile(t, "config.json", "{"))
+			},
+		},
+		"not an object": {
+			create: func(t *testing.T) (*environ.ConfigFileSource, error) {
+				return environ.NewYAMLFileSource(writeConfigFile(t, "config.yaml", "- a\n- b"))
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := tc.create(t)
+			if err == nil {
+				slog.Error("expected an error creating config file source")
+				t.Fail()
+			}
+		})
+	}
+
+	// values loaded into slices of structs must be arrays of objects
+	notObjects, err := environ.NewJSONFileSource(writeConfigFile(t, "config.json", `{"servers": ["a.internal"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		cfg    exampleConfigFileConfig
+		envErr *environ.EnvError
+	)
+	err = environ.LoadWith(&cfg, notObjects)
+	if !errors.As(err, &envErr) || envErr.Err != environ.ErrLoading || envErr.Key != "Servers" || envErr.Source != "config" {
+		slog.Error("expected a loading error for servers", "error", err)
+		t.Fail()
+	}
+
+	// a file that breaks after the source is created fails the load
+	path := writeConfigFile(t, "config.toml", `name = "service"`)
+	source, err := environ.NewTOMLFileSource(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte(`password = hunter2`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg = exampleConfigFileConfig{}
+	err = environ.LoadWith(&cfg, source)
+	if !errors.As(err, &envErr) || envErr.Err != environ.ErrLoading || envErr.Source != "config" {
+		slog.Error("expected a loading error", "error", err)
+		t.Fail()
+	}
+	// the parse error quotes the file, so it is only kept as the cause
+	if strings.Contains(err.Error(), "hunter") || envErr.Cause == nil {
+		slog.Error("expected the parse error to be left out of the message", "error", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,84 @@
+package environ
+
+import (
+	"encoding"
+	"reflect"
+	"sync"
+)
+
+// Decoder is implemented by types that can set themselves from a loaded value
+type Decoder interface {
+	Decode(value string) error
+}
+
+var (
+	decoderType           = reflect.TypeOf((*Decoder)(nil)).Elem()
+	textUnmarshalerType   = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+)
+
+// parserFunc parses a loaded value into a value of a registered type
+type parserFunc func(value string) (reflect.Value, error)
+
+// parsers holds the parser funcs registered for types
+var parsers = struct {
+	sync.RWMutex
+	funcs map[reflect.Type]parserFunc
+}{
+	funcs: map[reflect.Type]parserFunc{},
+}
+
+// RegisterParser registers a func to parse values of type T, for types that cannot implement Decoder such as types
+// from third party packages. Registered parsers take priority over every other way of setting a value, and apply to
+// every load after they are registered.
+func RegisterParser[T any](parse func(value string) (T, error)) {
+	parsers.Lock()
+	defer parsers.Unlock()
+	parsers.funcs[reflect.TypeOf((*T)(nil)).Elem()] = func(value string) (reflect.Value, error) {
+		v, err := parse(value)
+		return reflect.ValueOf(&v).Elem(), err
+	}
+}
+
+// returns the parser registered for a type, if there is one
+func getParser(t reflect.Type) (parserFunc, bool) {
+	parsers.RLock()
+	defer parsers.RUnlock()
+	parse, ok := parsers.funcs[t]
+	return parse, ok
+}
+
+// checks if a type is set by a registered parser, Decoder, encoding.TextUnmarshaler or encoding.BinaryUnmarshaler
+// instead of by its kind, or is a Secret or time set through setValue
+func isDecodable(t reflect.Type) bool {
+	if _, ok := getParser(t); ok || isTimeType(t) {
+		return true
+	}
+	ptr := reflect.PointerTo(t)
+	return ptr.Implements(secretWrapperType) || ptr.Implements(decoderType) || ptr.Implements(textUnmarshalerType) || ptr.Implements(binaryUnmarshalerType)
+}
+
+// sets the param with a registered parser, or by the first decoding interface it implements. Returns false when
+// none of them apply to the param.
+func decodeValue(param reflect.Value, value string) (bool, error) {
+	if parse, ok := getParser(param.Type()); ok {
+		v, err := parse(value)
+		if err != nil {
+			return true, err
+		}
+		param.Set(v)
+		return true, nil
+	}
+	if !param.CanAddr() {
+		return false, nil
+	}
+	switch p := param.Addr().Interface().(type) {
+	case Decoder:
+		return true, p.Decode(value)
+	case encoding.TextUnmarshaler:
+		return true, p.UnmarshalText([]byte(value))
+	case encoding.BinaryUnmarshaler:
+		return true, p.UnmarshalBinary([]byte(value))
+	}
+	return false, nil
+}
@@ -0,0 +1,126 @@
+package environ_test
+
+import (
+	"errors"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/NeedMoreVolume/environ"
+)
+
+var errUnknownColor = errors.New("unknown color")
+
+// color implements environ.Decoder
+type color int
+
+const (
+	red color = iota + 1
+	green
+)
+
+func (c *color) Decode(value string) error {
+	switch strings.ToLower(value) {
+	case "red":
+		*c = red
+	case "green":
+		*c = green
+	default:
+		return errUnknownColor
+	}
+	return nil
+}
+
+// thirdPartyVersion stands in for a type from a package that cannot be modified
+type thirdPartyVersion struct {
+	major, minor string
+}
+
+func init() {
+	environ.RegisterParser(func(value string) (thirdPartyVersion, error) {
+		major, minor, _ := strings.Cut(value, ".")
+		return thirdPartyVersion{major: major, minor: minor}, nil
+	})
+}
+
+type exampleDecodeConfig struct {
+	IP       net.IP                        `env:"MY_IP" default:"127.0.0.1"`
+	URL      *url.URL                      `env:"MY_URL" default:"https://example.com/path"`
+	Big      big.Int                       `env:"MY_BIG" default:"123456789012345678901234567890"`
+	Color    color                         `env:"MY_COLOR" default:"green"`
+	Colors   []color                       `env:"MY_COLORS" default:"red,green"`
+	ByColor  map[color]int                 `env:"MY_COLOR_MAP" default:"red:1,green:2"`
+	Version  thirdPartyVersion             `env:"MY_VERSION" default:"1.2"`
+	Versions map[string]*thirdPartyVersion `env:"MY_VERSIONS" default:"a:1.2"`
+	Unset    *url.URL                      `env:"MY_UNSET_URL"`
+}
+
+func TestLoadDecoders(t *testing.T) {
+	var cfg exampleDecodeConfig
+	err := environ.Load(&cfg)
+	if err != nil {
+		slog.Error("failed to load config", "error", err)
+		t.FailNow()
+	}
+	expectedBig, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	expected := exampleDecodeConfig{
+		IP:       net.ParseIP("127.0.0.1"),
+		URL:      &url.URL{Scheme: "https", Host: "example.com", Path: "/path"},
+		Big:      *expectedBig,
+		Color:    green,
+		Colors:   []color{red, green},
+		ByColor:  map[color]int{red: 1, green: 2},
+		Version:  thirdPartyVersion{major: "1", minor: "2"},
+		Versions: map[string]*thirdPartyVersion{"a": {major: "1", minor: "2"}},
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		slog.Error("expected result does not match result", "expected result", expected, "result", cfg)
+		t.Fail()
+	}
+}
+
+func TestLoadDecoderErrors(t *testing.T) {
+	testCases := map[string]struct {
+		env           map[string]string
+		expectedError environ.EnvError
+	}{
+		"decoder error": {
+			env: map[string]string{"MY_COLOR": "blue"},
+			expectedError: environ.EnvError{
+				Err:    environ.ErrInvalidFormat,
+				Key:    "Color",
+				Source: "env",
+				Extra:  "value could not be decoded",
+			},
+		},
+		"text unmarshaler error": {
+			env: map[string]string{"MY_IP": "not an ip"},
+			expectedError: environ.EnvError{
+				Err:    environ.ErrInvalidFormat,
+				Key:    "IP",
+				Source: "env",
+				Extra:  "value could not be decoded",
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			var (
+				cfg    exampleDecodeConfig
+				err    = environ.Load(&cfg)
+				envErr *environ.EnvError
+			)
+			if !errors.As(err, &envErr) || tc.expectedError != *envErr {
+				slog.Error("expected error didn't match error", "expected error", tc.expectedError, "error", err)
+				t.Fail()
+			}
+		})
+	}
+}
@@ -0,0 +1,376 @@
+package environ
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// errDotenvSyntax is returned for dotenv files that cannot be parsed
+var errDotenvSyntax = errors.New("invalid dotenv syntax")
+
+// DotenvSource reads values from .env files by `env` tags, so a local file can stand in for the environment. Files use
+// the common dotenv grammar:
+//
+//	# comments and blank lines are ignored
+//	export NAME=value
+//	PLAIN=value # trailing comments are ignored after whitespace
+//	SINGLE='no ${INTERPOLATION} or \escapes'
+//	DOUBLE="escapes like \n and \" and ${INTERPOLATION}"
+//	MULTI="values in quotes
+//	can span lines"
+//	WITH_DEFAULT=${UNSET:-fallback}
+//
+// Variables are interpolated from values earlier in the files, then from the environment of the current process.
+// Later files override earlier ones, and the files are read again at the start of every load.
+type DotenvSource struct {
+	paths []string
+
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// NewDotenvSource creates a DotenvSource for one or more files, the files are read when a load starts
+func NewDotenvSource(paths ...string) *DotenvSource {
+	return &DotenvSource{
+		paths:  paths,
+		values: map[string]string{},
+	}
+}
+
+// Tag returns the env tag
+func (s *DotenvSource) Tag() string {
+	return envTag
+}
+
+// Preload reads the files again, so changes to the files are picked up on every load
+func (s *DotenvSource) Preload(_ []string) error {
+	values := map[string]string{}
+	for _, path := range s.paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		err = parseDotenv(string(data), values)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = values
+	return nil
+}
+
+// Lookup returns the value of key from the files, empty values are treated as not found
+func (s *DotenvSource) Lookup(key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v := s.values[key]
+	return v, v != "", nil
+}
+
+// Keys lists the keys in the files that are set to a value
+func (s *DotenvSource) Keys() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	for key, value := range s.values {
+		if value != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// WithDotenv adds a DotenvSource for the files right after the env source, so variables set in the environment of
+// the process win over the files. Use WithDotenvOverride to let the files win instead. The source is added once every
+// option has run, so it works with WithSources given in any order.
+func WithDotenv(paths ...string) Option {
+	return func(l *loader) {
+		l.dotenv = append(l.dotenv, dotenvOption{source: NewDotenvSource(paths...)})
+	}
+}
+
+// WithDotenvOverride adds a DotenvSource for the files right before the env source, so values in the files win over
+// variables set in the environment of the process. The source is added once every option has run, so it works with
+// WithSources given in any order.
+func WithDotenvOverride(paths ...string) Option {
+	return func(l *loader) {
+		l.dotenv = append(l.dotenv, dotenvOption{source: NewDotenvSource(paths...), override: true})
+	}
+}
+
+// dotenvOption is a DotenvSource waiting to be added next to the env source of a load
+type dotenvOption struct {
+	source   *DotenvSource
+	override bool
+}
+
+// adds the sources from the dotenv options next to the env source, in the order the options were given
+func (l *loader) addDotenvSources() {
+	for _, d := range l.dotenv {
+		l.addEnvSource(d.source, d.override)
+	}
+}
+
+// adds a source next to the first env source, before it when the source should win and after it otherwise. The
+// source is added last when there is no env source.
+func (l *loader) addEnvSource(source Source, before bool) {
+	for i, s := range l.sources {
+		if s.Tag() != envTag {
+			continue
+		}
+		if !before {
+			i++
+		}
+		l.sources = append(l.sources[:i], append([]Source{source}, l.sources[i:]...)...)
+		return
+	}
+	l.sources = append(l.sources, source)
+}
+
+// dotenvParser walks the contents of a dotenv file
+type dotenvParser struct {
+	data   string
+	pos    int
+	line   int
+	values map[string]string
+}
+
+// parses the contents of a dotenv file into values, values already in the map can be interpolated and are
+// overridden by the file
+func parseDotenv(data string, values map[string]string) error {
+	p := &dotenvParser{
+		data:   strings.ReplaceAll(data, "\r\n", "\n"),
+		line:   1,
+		values: values,
+	}
+	for {
+		p.skipSpace(true)
+		if p.done() {
+			return nil
+		}
+		if p.peek() == '#' {
+			p.skipLine()
+			continue
+		}
+		key := p.readKey()
+		if key == "export" && !p.done() && (p.peek() == ' ' || p.peek() == '\t') {
+			p.skipSpace(false)
+			key = p.readKey()
+		}
+		if key == "" {
+			return p.errorf("expected a variable name")
+		}
+		p.skipSpace(false)
+		if p.done() || p.peek() != '=' {
+			return p.errorf("expected = after %s", key)
+		}
+		p.pos++
+		p.skipSpace(false)
+		value, err := p.readValue()
+		if err != nil {
+			return err
+		}
+		values[key] = value
+	}
+}
+
+func (p *dotenvParser) done() bool {
+	return p.pos >= len(p.data)
+}
+
+func (p *dotenvParser) peek() byte {
+	return p.data[p.pos]
+}
+
+func (p *dotenvParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: line %d: %s", errDotenvSyntax, p.line, fmt.Sprintf(format, args...))
+}
+
+// skips spaces and tabs, and newlines when newlines is true
+func (p *dotenvParser) skipSpace(newlines bool) {
+	for !p.done() {
+		switch p.peek() {
+		case ' ', '\t':
+		case '\n':
+			if !newlines {
+				return
+			}
+			p.line++
+		default:
+			return
+		}
+		p.pos++
+	}
+}
+
+// skips to the start of the next line
+func (p *dotenvParser) skipLine() {
+	for !p.done() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+// reads a variable name made of letters, digits, underscores, dots and dashes
+func (p *dotenvParser) readKey() string {
+	start := p.pos
+	for !p.done() && isDotenvKeyChar(p.peek()) {
+		p.pos++
+	}
+	return p.data[start:p.pos]
+}
+
+func isDotenvKeyChar(c byte) bool {
+	return c == '_' || c == '.' || c == '-' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// reads a quoted or unquoted value and the rest of its line
+func (p *dotenvParser) readValue() (string, error) {
+	var (
+		value string
+		err   error
+	)
+	if !p.done() && (p.peek() == '\'' || p.peek() == '"') {
+		value, err = p.readQuoted(p.peek())
+		if err != nil {
+			return "", err
+		}
+		// only a comment can follow a quoted value
+		p.skipSpace(false)
+		if !p.done() && p.peek() != '\n' && p.peek() != '#' {
+			return "", p.errorf("unexpected text after quoted value")
+		}
+		p.skipLine()
+		return value, nil
+	}
+	start := p.pos
+	for !p.done() && p.peek() != '\n' {
+		// a comment starts at a # after whitespace
+		if p.peek() == '#' && p.pos > start && (p.data[p.pos-1] == ' ' || p.data[p.pos-1] == '\t') {
+			break
+		}
+		p.pos++
+	}
+	raw := strings.TrimSpace(p.data[start:p.pos])
+	p.skipLine()
+	return p.interpolate(unescapeDollars(raw)), nil
+}
+
+// reads a value in single or double quotes, which can span lines. Single quoted values are used as they are, double
+// quoted values support escapes and interpolation.
+func (p *dotenvParser) readQuoted(quote byte) (string, error) {
+	line := p.line
+	p.pos++
+	var (
+		b strings.Builder
+		// the offsets of escaped dollars, which are not interpolated
+		literal = map[int]bool{}
+	)
+	for !p.done() {
+		c := p.peek()
+		p.pos++
+		switch {
+		case c == quote:
+			if quote == '\'' {
+				return b.String(), nil
+			}
+			return p.interpolate(b.String(), literal), nil
+		case c == '\n':
+			p.line++
+		case c == '\\' && quote == '"' && !p.done():
+			c = p.peek()
+			p.pos++
+			switch c {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '$':
+				literal[b.Len()] = true
+				b.WriteByte('$')
+			case '"', '\\':
+				b.WriteByte(c)
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(c)
+			}
+			continue
+		}
+		b.WriteByte(c)
+	}
+	p.line = line
+	return "", p.errorf("unterminated quoted value")
+}
+
+// replaces \$ in an unquoted value with $, returning the offsets of the replaced dollars so they are not interpolated
+func unescapeDollars(value string) (string, map[int]bool) {
+	var (
+		b       strings.Builder
+		literal = map[int]bool{}
+	)
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) && value[i+1] == '$' {
+			literal[b.Len()] = true
+			i++
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String(), literal
+}
+
+// replaces $VAR, ${VAR}, ${VAR:-default} and ${VAR-default} with values from earlier in the files or the
+// environment. Dollars at the offsets in literal were escaped and are kept as they are.
+func (p *dotenvParser) interpolate(value string, literal map[int]bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c != '$' || literal[i] || i+1 >= len(value) {
+			b.WriteByte(c)
+			continue
+		}
+		if value[i+1] == '{' {
+			end := strings.IndexByte(value[i:], '}')
+			if end < 0 {
+				b.WriteByte(c)
+				continue
+			}
+			expr := value[i+2 : i+end]
+			i += end
+			name, fallback, hasFallback := strings.Cut(expr, "-")
+			name, colon := strings.CutSuffix(name, ":")
+			v, found := p.lookup(name)
+			if hasFallback && (!found || colon && v == "") {
+				v = fallback
+			}
+			b.WriteString(v)
+			continue
+		}
+		end := i + 1
+		// names without braces end at the first character that is not a letter, digit or underscore
+		for end < len(value) && isDotenvKeyChar(value[end]) && value[end] != '.' && value[end] != '-' {
+			end++
+		}
+		if end == i+1 {
+			b.WriteByte(c)
+			continue
+		}
+		v, _ := p.lookup(value[i+1 : end])
+		b.WriteString(v)
+		i = end - 1
+	}
+	return b.String()
+}
+
+// looks up a variable from earlier in the files, then from the environment
+func (p *dotenvParser) lookup(name string) (string, bool) {
+	if v, ok := p.values[name]; ok {
+		return v, true
+	}
+	return os.LookupEnv(name)
+}
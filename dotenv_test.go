@@ -0,0 +1,147 @@
+package environ_test
+
+import (
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/NeedMoreVolume/environ"
+)
+
+const exampleDotenv = `# local settings
+export DOTENV_HOST=localhost
+DOTENV_PORT = 8080 # trailing comment
+DOTENV_URL=http://${DOTENV_HOST}:$DOTENV_PORT/api
+DOTENV_SINGLE='raw ${DOTENV_HOST} \n'
+DOTENV_DOUBLE="tab\there \"quoted\" \${DOTENV_HOST}"
+DOTENV_MULTI="first line
+second line"
+DOTENV_HASH=a#b
+DOTENV_FALLBACK=${DOTENV_UNSET:-fallback}
+DOTENV_FROM_ENV=${DOTENV_PROCESS}
+DOTENV_EMPTY=
+DOTENV_TAGS=a,b,c
+DOTENV_BACKSLASH="C:\\$DOTENV_HOST"
+DOTENV_PRICE=\$5
+`
+
+type exampleDotenvConfig struct {
+	Host      string   `env:"DOTENV_HOST"`
+	Port      int      `env:"DOTENV_PORT"`
+	URL       string   `env:"DOTENV_URL"`
+	Single    string   `env:"DOTENV_SINGLE"`
+	Double    string   `env:"DOTENV_DOUBLE"`
+	Multi     string   `env:"DOTENV_MULTI"`
+	Hash      string   `env:"DOTENV_HASH"`
+	Fallback  string   `env:"DOTENV_FALLBACK"`
+	FromEnv   string   `env:"DOTENV_FROM_ENV"`
+	Empty     string   `env:"DOTENV_EMPTY" default:"default"`
+	Tags      []string `env:"DOTENV_TAGS"`
+	Backslash string   `env:"DOTENV_BACKSLASH"`
+	Price     string   `env:"DOTENV_PRICE"`
+}
+
+func TestDotenvSource(t *testing.T) {
+	path := writeConfigFile(t, ".env", exampleDotenv)
+	t.Setenv("DOTENV_PROCESS", "process")
+	t.Setenv("DOTENV_PORT", "9090")
+
+	expected := exampleDotenvConfig{
+		Host:      "localhost",
+		Port:      9090,
+		URL:       "http://localhost:8080/api",
+		Single:    `raw ${DOTENV_HOST} \n`,
+		Double:    "tab\there \"quoted\" ${DOTENV_HOST}",
+		Multi:     "first line\nsecond line",
+		Hash:      "a#b",
+		Fallback:  "fallback",
+		FromEnv:   "process",
+		Empty:     "default",
+		Tags:      []string{"a", "b", "c"},
+		Backslash: `C:\localhost`,
+		Price:     "$5",
+	}
+	var cfg exampleDotenvConfig
+	err := environ.Load(&cfg, environ.WithDotenv(path))
+	if err != nil {
+		slog.Error("failed to load dotenv config", "error", err)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		slog.Error("expected result does not match result", "expected result", expected, "result", cfg)
+		t.Fail()
+	}
+
+	// the file wins over the environment when overriding
+	cfg = exampleDotenvConfig{}
+	err = environ.Load(&cfg, environ.WithDotenvOverride(path))
+	if err != nil || cfg.Port != 8080 {
+		slog.Error("dotenv file did not override the environment", "port", cfg.Port, "error", err)
+		t.Fail()
+	}
+
+	// the file is added next to the env source whatever order the options are given in
+	cfg = exampleDotenvConfig{}
+	err = environ.Load(&cfg, environ.WithDotenvOverride(path), environ.WithSources(environ.EnvSource()))
+	if err != nil || cfg.Port != 8080 {
+		slog.Error("dotenv file was dropped by a later WithSources", "port", cfg.Port, "error", err)
+		t.Fail()
+	}
+
+	// later files override earlier ones
+	override := writeConfigFile(t, ".env.local", "DOTENV_HOST=override")
+	cfg = exampleDotenvConfig{}
+	err = environ.LoadWith(&cfg, environ.NewDotenvSource(path, override))
+	if err != nil || cfg.Host != "override" || cfg.URL != "http://localhost:8080/api" {
+		slog.Error("later dotenv file did not override earlier file", "host", cfg.Host, "url", cfg.URL, "error", err)
+		t.Fail()
+	}
+}
+
+func TestDotenvSourceStructCollections(t *testing.T) {
+	path := writeConfigFile(t, ".env", "BACKENDS_0_HOST=a\nBACKENDS_1_HOST=b\n")
+	var cfg struct {
+		Backends []struct {
+			Host string `env:"HOST"`
+		} `env:"BACKENDS"`
+	}
+	err := environ.Load(&cfg, environ.WithDotenv(path))
+	if err != nil || len(cfg.Backends) != 2 || cfg.Backends[1].Host != "b" {
+		slog.Error("failed to load indexed keys from dotenv file", "result", cfg, "error", err)
+		t.Fail()
+	}
+}
+
+func TestDotenvSourceErrors(t *testing.T) {
+	testCases := map[string]struct {
+		contents string
+	}{
+		"missing equals":      {contents: "KEY value"},
+		"missing name":        {contents: "=value"},
+		"unterminated quote":  {contents: "KEY=\"value\nOTHER=1"},
+		"text after a quote":  {contents: "KEY='value' extra"},
+		"invalid export line": {contents: "export"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var (
+				cfg    exampleDotenvConfig
+				envErr *environ.EnvError
+			)
+			err := environ.Load(&cfg, environ.WithDotenv(writeConfigFile(t, ".env", tc.contents)))
+			if !errors.As(err, &envErr) || envErr.Err != environ.ErrLoading {
+				slog.Error("expected a loading error", "error", err)
+				t.Fail()
+			}
+		})
+	}
+
+	var cfg exampleDotenvConfig
+	err := environ.Load(&cfg, environ.WithDotenv(filepath.Join(t.TempDir(), ".env")))
+	if err == nil {
+		slog.Error("expected an error for a missing dotenv file")
+		t.Fail()
+	}
+}
@@ -18,24 +18,42 @@ var (
 	ErrUnsupportedType = errors.New("has unsupported type")
 	// ErrUnsettableParam is the error for unsettable params, or unexported fields encountered in a struct
 	ErrUnsettableParam = errors.New("must be a settable parameter")
+	// ErrMissingTag is the error for fields without a tag for any source, reported by the WithStrict option
+	ErrMissingTag = errors.New("has no tags")
+	// ErrOutOfRange is the error for numbers and durations outside of their min and max tags
+	ErrOutOfRange = errors.New("is out of range")
+	// ErrInvalidLength is the error for strings, slices and maps that do not match their len, minlen and maxlen tags
+	ErrInvalidLength = errors.New("has invalid length")
+	// ErrNotOneOf is the error for values that are not one of the options in their oneof tag
+	ErrNotOneOf = errors.New("is not an allowed value")
+	// ErrPatternMismatch is the error for strings that do not match their regex tag
+	ErrPatternMismatch = errors.New("does not match pattern")
 )
 
-// EnvError implements the error interface with key infomation and some helpful text for fixing the issues with loading a config
+// EnvError implements the error interface with key infomation and some helpful text for fixing the issues with loading a config.
+// Source is the tag of the source that supplied the value, or the tags of the sources that failed to supply one.
+// Cause is the error returned by a source that could not be read, such as a denied request or an insecure file.
 type EnvError struct {
-	Err   error
-	Key   string
-	Extra string
+	Err    error
+	Key    string
+	Source string
+	Extra  string
+	Cause  error
 }
 
 // Error returns a user friendly error message in the format below
 //
-//	env: <key> <err message> | extra: <extra>
+//	env: <key> <err message> | source: <source> | extra: <extra>
 func (e *EnvError) Error() string {
 	var sb strings.Builder
 	sb.WriteString("env: ")
 	sb.WriteString(e.Key)
 	sb.WriteString(" ")
 	sb.WriteString(e.Err.Error())
+	if e.Source != "" {
+		sb.WriteString(" | source: ")
+		sb.WriteString(e.Source)
+	}
 	if e.Extra != "" {
 		sb.WriteString(" | extra: ")
 		sb.WriteString(e.Extra)
@@ -43,6 +61,42 @@ func (e *EnvError) Error() string {
 	return sb.String()
 }
 
+// Unwrap returns the underlying sentinel error and the cause when there is one, so errors.Is can be used to check the
+// kind of error and why a source could not be read
+func (e *EnvError) Unwrap() []error {
+	if e.Cause == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Err, e.Cause}
+}
+
+// MultiError collects every EnvError encountered while loading a config, so all of the problems with a config can be
+// reported at once. It supports errors.Is and errors.As the same way as errors created by errors.Join.
+type MultiError struct {
+	Errors []*EnvError
+}
+
+// Error returns the messages of each error, separated by newlines
+func (e *MultiError) Error() string {
+	var sb strings.Builder
+	for i, err := range e.Errors {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(err.Error())
+	}
+	return sb.String()
+}
+
+// Unwrap returns each of the collected errors
+func (e *MultiError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i := range e.Errors {
+		errs[i] = e.Errors[i]
+	}
+	return errs
+}
+
 func newError(err error, key, extra string) *EnvError {
 	return &EnvError{
 		Err:   err,
@@ -50,3 +104,18 @@ func newError(err error, key, extra string) *EnvError {
 		Extra: extra,
 	}
 }
+
+func newSourceError(err error, key, source, extra string) *EnvError {
+	e := newError(err, key, extra)
+	e.Source = source
+	return e
+}
+
+// returns an ErrLoading error for a source that could not be read, keeping the error from the source as the cause.
+// The cause is left out of the message since it can quote raw values, such as the line of a config file that failed
+// to parse.
+func newLoadingError(key, source, extra string, cause error) *EnvError {
+	e := newSourceError(ErrLoading, key, source, extra)
+	e.Cause = cause
+	return e
+}
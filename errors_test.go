@@ -1,6 +1,7 @@
 package environ_test
 
 import (
+	"errors"
 	"log/slog"
 	"testing"
 
@@ -27,6 +28,15 @@ func TestErrors(t *testing.T) {
 			},
 			expectedOutput: "env: 2 must be a pointer to a struct | extra: need valid input for parameters",
 		},
+		"with source": {
+			envErr: environ.EnvError{
+				Err:    environ.ErrInvalidFormat,
+				Key:    "3",
+				Source: "ssm",
+				Extra:  "value is not a valid integer representation",
+			},
+			expectedOutput: "env: 3 has invalid format | source: ssm | extra: value is not a valid integer representation",
+		},
 	}
 	for name, tc := range testCases {
 		t.Run(name, func(t *testing.T) {
@@ -38,3 +48,39 @@ func TestErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestMultiError(t *testing.T) {
+	multiErr := &environ.MultiError{
+		Errors: []*environ.EnvError{
+			{Err: environ.ErrRequiredNotFound, Key: "Password", Source: "env", Extra: "required field not loaded"},
+			{Err: environ.ErrInvalidFormat, Key: "Port", Source: "env", Extra: "value is not a valid integer representation"},
+		},
+	}
+	expectedOutput := "env: Password is required but failed to load value | source: env | extra: required field not loaded\n" +
+		"env: Port has invalid format | source: env | extra: value is not a valid integer representation"
+	if multiErr.Error() != expectedOutput {
+		slog.Error("output does not match expected output", "output", multiErr.Error(), "expected output", expectedOutput)
+		t.Fail()
+	}
+	var err error = multiErr
+	if !errors.Is(err, environ.ErrRequiredNotFound) || !errors.Is(err, environ.ErrInvalidFormat) || errors.Is(err, environ.ErrLoading) {
+		slog.Error("errors.Is does not match the collected errors")
+		t.Fail()
+	}
+	var envErr *environ.EnvError
+	if !errors.As(err, &envErr) || envErr.Key != "Password" {
+		slog.Error("errors.As does not return the first collected error")
+		t.Fail()
+	}
+	// the cause of a loading error is reachable through the MultiError
+	cause := errors.New("access denied")
+	err = &environ.MultiError{
+		Errors: []*environ.EnvError{
+			{Err: environ.ErrLoading, Key: "Password", Source: "ssm", Cause: cause},
+		},
+	}
+	if !errors.Is(err, environ.ErrLoading) || !errors.Is(err, cause) {
+		slog.Error("errors.Is does not match the cause of a loading error")
+		t.Fail()
+	}
+}
@@ -0,0 +1,66 @@
+package environ
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// fileSuffix is added to an env key to find the variable holding the path of a file with its value, IE: MYSQL_PASSWORD_FILE
+const fileSuffix = "_FILE"
+
+var (
+	// errNotRegularFile is returned for secret files that are directories, devices or other special files
+	errNotRegularFile = errors.New("secret file is not a regular file")
+	// errInsecureFile is returned for secret files that can be written by any user
+	errInsecureFile = errors.New("secret file is writable by other users")
+)
+
+// fileSource reads values from the contents of files, such as Docker and Kubernetes secrets
+type fileSource struct{}
+
+// FileSource returns the built-in Source that reads the files named by `file` tags, IE:
+// `file:"/run/secrets/db_password"`. Files that do not exist are treated as not found, so defaults still apply.
+func FileSource() Source {
+	return fileSource{}
+}
+
+// Tag returns the file tag
+func (fileSource) Tag() string {
+	return fileTag
+}
+
+// Lookup reads the file at the path in key, empty files are treated as not found
+func (fileSource) Lookup(key string) (string, bool, error) {
+	v, err := readSecretFile(key)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return v, v != "", nil
+}
+
+// reads a secret from a file, trimming trailing newlines. Files must be regular files that other users cannot write
+// to, so a secret cannot be swapped out from under the process. Windows does not have Unix permissions, so the write
+// check is skipped there.
+func readSecretFile(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", errNotRegularFile
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o002 != 0 {
+		return "", errInsecureFile
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
@@ -0,0 +1,149 @@
+package environ_test
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/NeedMoreVolume/environ"
+)
+
+type exampleFileConfig struct {
+	Password string   `env:"FILE_TEST_PASSWORD" required:"true"`
+	Missing  string   `file:"/run/secrets/environ_missing" default:"fallback"`
+	Hosts    []string `env:"FILE_TEST_HOSTS"`
+}
+
+func TestFileSource(t *testing.T) {
+	dir := t.TempDir()
+	passwordPath := filepath.Join(dir, "password")
+	tokenPath := filepath.Join(dir, "token")
+	hostsPath := filepath.Join(dir, "hosts")
+	for path, contents := range map[string]string{
+		passwordPath: "hunter2\n",
+		tokenPath:    "abc123\r\n\n",
+		hostsPath:    "a,b",
+	} {
+		err := os.WriteFile(path, []byte(contents), 0o640)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("FILE_TEST_PASSWORD_FILE", passwordPath)
+	t.Setenv("FILE_TEST_HOSTS_FILE", hostsPath)
+
+	var cfg exampleFileConfig
+	err := environ.Load(&cfg)
+	if err != nil {
+		slog.Error("failed to load file config", "error", err)
+		t.FailNow()
+	}
+	expected := exampleFileConfig{
+		Password: "hunter2",
+		Missing:  "fallback",
+		Hosts:    []string{"a", "b"},
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		slog.Error("expected result does not match result", "expected result", expected, "result", cfg)
+		t.Fail()
+	}
+
+	// the variable wins over its _FILE variable
+	t.Setenv("FILE_TEST_PASSWORD", "from-env")
+	cfg = exampleFileConfig{}
+	err = environ.Load(&cfg)
+	if err != nil || cfg.Password != "from-env" {
+		slog.Error("expected the variable to win over its _FILE variable", "password", cfg.Password, "error", err)
+		t.Fail()
+	}
+
+	// a _FILE variable naming a file that does not exist is treated as not found, like a file tag
+	t.Setenv("FILE_TEST_HOSTS", "")
+	t.Setenv("FILE_TEST_HOSTS_FILE", filepath.Join(dir, "missing"))
+	cfg = exampleFileConfig{}
+	err = environ.Load(&cfg)
+	if err != nil || cfg.Hosts != nil {
+		slog.Error("expected a missing _FILE file to be treated as not found", "hosts", cfg.Hosts, "error", err)
+		t.Fail()
+	}
+
+	// file tags are checked when other sources are given
+	var (
+		required struct {
+			Token string `file:"/run/secrets/environ_missing" required:"true"`
+		}
+		envErr *environ.EnvError
+	)
+	err = environ.LoadWith(&required, environ.EnvSource())
+	if !errors.As(err, &envErr) || envErr.Err != environ.ErrRequiredNotFound || envErr.Source != "file" {
+		slog.Error("expected the file source to be checked", "error", err)
+		t.Fail()
+	}
+
+	// file tags are looked up by path with trailing newlines trimmed
+	value, found, err := environ.FileSource().Lookup(tokenPath)
+	if err != nil || !found || value != "abc123" {
+		slog.Error("failed to read file", "value", value, "found", found, "error", err)
+		t.Fail()
+	}
+}
+
+func TestFileSourceErrors(t *testing.T) {
+	dir := t.TempDir()
+	insecure := filepath.Join(dir, "insecure")
+	for path, mode := range map[string]os.FileMode{insecure: 0o666} {
+		err := os.WriteFile(path, []byte("hunter2"), mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the umask may have removed permissions
+		err = os.Chmod(path, mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := map[string]struct {
+		prep          func(t *testing.T)
+		expectedCause string
+	}{
+		"world writable file": {
+			prep: func(t *testing.T) {
+				if runtime.GOOS == "windows" {
+					t.Skip("windows does not have unix permissions")
+				}
+				t.Setenv("FILE_TEST_PASSWORD_FILE", insecure)
+			},
+			expectedCause: "secret file is writable by other users",
+		},
+		"directory": {
+			prep: func(t *testing.T) {
+				t.Setenv("FILE_TEST_PASSWORD_FILE", dir)
+			},
+			expectedCause: "secret file is not a regular file",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tc.prep(t)
+			var (
+				cfg    exampleFileConfig
+				envErr *environ.EnvError
+			)
+			err := environ.Load(&cfg)
+			if !errors.As(err, &envErr) || envErr.Err != environ.ErrLoading || envErr.Key != "Password" || envErr.Cause == nil {
+				slog.Error("expected a loading error", "error", err)
+				t.FailNow()
+			}
+			// the error from the source is kept as the cause, but is left out of the message
+			if envErr.Extra != "failed to read value from env source" || envErr.Cause.Error() != tc.expectedCause || !errors.Is(err, envErr.Cause) {
+				slog.Error("expected the cause of the loading error", "extra", envErr.Extra, "cause", envErr.Cause, "expected cause", tc.expectedCause)
+				t.Fail()
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package environ
+
+import (
+	"flag"
+	"reflect"
+)
+
+// FlagSource reads values from command-line flags by `flag` tags, IE: `flag:"port"`. Flags are registered on a
+// flag.FlagSet by RegisterFlags and only flags that were set on the command line are considered found, so the other
+// sources and the default tag still apply to the rest.
+type FlagSource struct {
+	flags map[string]*flagValue
+}
+
+// RegisterFlags registers a flag on the flag set for every field of the config with a `flag` tag, including fields of
+// nested structs, and returns the FlagSource that reads them. The `default` tag is shown as the default of the flag and
+// the `desc` tag as its usage. Values are checked against the type of the field when the flags are parsed, and bool
+// fields are registered as bool flags so they can be set without a value, IE: -verbose.
+//
+// Call Parse on the flag set before loading, and pass the source to the WithFlags option.
+func RegisterFlags(fs *flag.FlagSet, config any) (*FlagSource, error) {
+	configStruct, err := validateConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	s := &FlagSource{flags: map[string]*flagValue{}}
+	err = s.register(fs, configStruct.Type(), map[reflect.Type]bool{})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// WithFlags puts the FlagSource before every other source, so flags set on the command line win over every other way
+// of setting a value. The source is added once every option has run, so it stays first with WithSources given in any
+// order.
+func WithFlags(source *FlagSource) Option {
+	return func(l *loader) {
+		l.flags = source
+	}
+}
+
+// Tag returns the flag tag
+func (s *FlagSource) Tag() string {
+	return flagTag
+}
+
+// Lookup returns the value of the flag named by key, flags that were not set are treated as not found
+func (s *FlagSource) Lookup(key string) (string, bool, error) {
+	f, ok := s.flags[key]
+	if !ok || !f.set {
+		return "", false, nil
+	}
+	return f.value, true, nil
+}
+
+// registers the flags for the fields of a struct type and its nested structs. A struct type nested in more than one
+// field registers its flags for each field, which fails since the flag is already defined.
+func (s *FlagSource) register(fs *flag.FlagSet, structType reflect.Type, walking map[reflect.Type]bool) error {
+	// recursive types are not walked again within themselves
+	if walking[structType] {
+		return nil
+	}
+	walking[structType] = true
+	defer delete(walking, structType)
+	for i := 0; i < structType.NumField(); i++ {
+		structField := structType.Field(i)
+		fieldType := structField.Type
+		// slices and maps of structs are loaded from indexed keys, which flags do not support
+		if isStructCollection(fieldType) {
+			continue
+		}
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct && !isDecodable(fieldType) {
+			err := s.register(fs, fieldType, walking)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		name, found := structField.Tag.Lookup(flagTag)
+		if !found {
+			continue
+		}
+		if fs.Lookup(name) != nil {
+			return newSourceError(ErrInvalidFormat, structField.Name, flagTag, "flag is already defined")
+		}
+		f := &flagValue{
+			structField: structField,
+			value:       structField.Tag.Get(defaultTag),
+		}
+		fs.Var(f, name, structField.Tag.Get(descTag))
+		s.flags[name] = f
+	}
+	return nil
+}
+
+// flagValue implements flag.Value for a field, keeping the raw value for the FlagSource
+type flagValue struct {
+	structField reflect.StructField
+	value       string
+	set         bool
+}
+
+// String returns the value of the flag, which is the default until the flag is set
+func (f *flagValue) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.value
+}
+
+// Set checks the value can be set on the field and passes its validation tags before keeping it
+func (f *flagValue) Set(value string) error {
+	param := reflect.New(f.structField.Type).Elem()
+	err := setValue(f.structField, param, value)
+	if err != nil {
+		return err
+	}
+	err = validateField(f.structField, param)
+	if err != nil {
+		return err
+	}
+	f.value = value
+	f.set = true
+	return nil
+}
+
+// IsBoolFlag lets bool fields be set without a value
+func (f *flagValue) IsBoolFlag() bool {
+	return f.structField.Type.Kind() == reflect.Bool
+}
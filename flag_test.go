@@ -0,0 +1,127 @@
+package environ_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NeedMoreVolume/environ"
+)
+
+type exampleFlagConfig struct {
+	Host    string        `env:"FLAG_TEST_HOST" flag:"host" default:"localhost" desc:"host to listen on"`
+	Port    int           `env:"FLAG_TEST_PORT" flag:"port" default:"8080" desc:"port to listen on"`
+	Verbose bool          `flag:"verbose" desc:"log every request"`
+	Timeout time.Duration `flag:"timeout" default:"5s"`
+	Tags    []string      `env:"FLAG_TEST_TAGS" flag:"tags"`
+	Unset   string        `env:"FLAG_TEST_UNSET" flag:"unset"`
+	DB      *struct {
+		Name string `flag:"db-name"`
+	}
+}
+
+func TestFlagSource(t *testing.T) {
+	t.Setenv("FLAG_TEST_HOST", "env.internal")
+	t.Setenv("FLAG_TEST_PORT", "9090")
+	t.Setenv("FLAG_TEST_UNSET", "from env")
+
+	var cfg exampleFlagConfig
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags, err := environ.RegisterFlags(fs, &cfg)
+	if err != nil {
+		slog.Error("failed to register flags", "error", err)
+		t.FailNow()
+	}
+	err = fs.Parse([]string{"-port", "7070", "-verbose", "-timeout=1m", "-tags", "a,b", "-db-name", "app"})
+	if err != nil {
+		slog.Error("failed to parse flags", "error", err)
+		t.FailNow()
+	}
+	err = environ.Load(&cfg, environ.WithFlags(flags))
+	if err != nil {
+		slog.Error("failed to load flag config", "error", err)
+		t.FailNow()
+	}
+	expected := exampleFlagConfig{
+		Host:    "env.internal",
+		Port:    7070,
+		Verbose: true,
+		Timeout: time.Minute,
+		Tags:    []string{"a", "b"},
+		Unset:   "from env",
+		DB: &struct {
+			Name string `flag:"db-name"`
+		}{Name: "app"},
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		slog.Error("expected result does not match result", "expected result", expected, "result", cfg)
+		t.Fail()
+	}
+
+	// flags win whatever order the options are given in
+	cfg = exampleFlagConfig{}
+	err = environ.Load(&cfg, environ.WithFlags(flags), environ.WithSources(environ.EnvSource()))
+	if err != nil || cfg.Port != 7070 {
+		slog.Error("flags were dropped by a later WithSources", "port", cfg.Port, "error", err)
+		t.Fail()
+	}
+
+	// defaults and descriptions are shown in the usage
+	var usage bytes.Buffer
+	fs.SetOutput(&usage)
+	fs.PrintDefaults()
+	for _, line := range []string{"host to listen on (default localhost)", "port to listen on (default 8080)", "-verbose"} {
+		if !strings.Contains(usage.String(), line) {
+			slog.Error("usage is missing a line", "line", line, "usage", usage.String())
+			t.Fail()
+		}
+	}
+}
+
+func TestFlagSourceErrors(t *testing.T) {
+	var cfg exampleFlagConfig
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&bytes.Buffer{})
+	_, err := environ.RegisterFlags(fs, &cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = fs.Parse([]string{"-port", "not a number"})
+	if err == nil {
+		slog.Error("expected an error parsing an invalid flag value")
+		t.Fail()
+	}
+
+	// registering the same flags again fails
+	var envErr *environ.EnvError
+	_, err = environ.RegisterFlags(fs, &cfg)
+	if !errors.As(err, &envErr) || envErr.Err != environ.ErrInvalidFormat {
+		slog.Error("expected an error registering a flag twice", "error", err)
+		t.Fail()
+	}
+
+	// a struct with flags that is nested twice would set both fields from one flag
+	type server struct {
+		Host string `flag:"host"`
+	}
+	var twice struct {
+		Primary server `envPrefix:"PRIMARY_"`
+		Replica server `envPrefix:"REPLICA_"`
+	}
+	_, err = environ.RegisterFlags(flag.NewFlagSet("twice", flag.ContinueOnError), &twice)
+	if !errors.As(err, &envErr) || envErr.Err != environ.ErrInvalidFormat || envErr.Key != "Host" {
+		slog.Error("expected an error registering a nested struct twice", "error", err)
+		t.Fail()
+	}
+
+	_, err = environ.RegisterFlags(fs, cfg)
+	if !errors.As(err, &envErr) || envErr.Err != environ.ErrInvalidInput {
+		slog.Error("expected an error registering flags for a non pointer", "error", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,148 @@
+package environ
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// defaultGSMEndpoint is the GCP Secret Manager REST API endpoint
+	defaultGSMEndpoint = "https://secretmanager.googleapis.com"
+	// latestGSMVersion is the alias for the newest enabled version of a secret
+	latestGSMVersion = "latest"
+)
+
+var (
+	// errGSMNoProject is returned for short form gsm tags when no default project is configured
+	errGSMNoProject = errors.New("gsm tag does not include a project and no default project is configured")
+	// errGSMStatus is returned for unexpected responses from the Secret Manager API
+	errGSMStatus = errors.New("unexpected response from secret manager")
+	// errGSMChecksum is returned when the payload of a secret does not match its checksum
+	errGSMChecksum = errors.New("secret payload failed checksum validation")
+)
+
+// GSMSource reads values from GCP Secret Manager by `gsm` tags, IE:
+// `gsm:"projects/my-project/secrets/db-password/versions/latest"`. Tags can leave out the version to use the latest
+// one, and the project to use the default project of the source, IE: `gsm:"db-password"` or
+// `gsm:"db-password/versions/3"`. Each secret version is fetched once per load.
+type GSMSource struct {
+	client   *http.Client
+	project  string
+	endpoint string
+
+	mu      sync.Mutex
+	secrets map[string]*string
+}
+
+// NewGSMSource creates a GSMSource. The client is expected to authenticate its requests, IE: one created by
+// google.DefaultClient from golang.org/x/oauth2/google. The project is used for tags that only name a secret and can be
+// empty when every tag is a full resource name. The endpoint overrides the Secret Manager API endpoint when it is not
+// empty, IE: to use a local fake of the API.
+func NewGSMSource(client *http.Client, project, endpoint string) *GSMSource {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if endpoint == "" {
+		endpoint = defaultGSMEndpoint
+	}
+	return &GSMSource{
+		client:   client,
+		project:  project,
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		secrets:  map[string]*string{},
+	}
+}
+
+// Tag returns the gsm tag
+func (s *GSMSource) Tag() string {
+	return gsmTag
+}
+
+// Preload clears any previously fetched secrets so values are refreshed on every load
+func (s *GSMSource) Preload(_ []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.secrets = map[string]*string{}
+	return nil
+}
+
+// Lookup returns the payload of the secret version named by key
+func (s *GSMSource) Lookup(key string) (string, bool, error) {
+	name, err := s.resourceName(key)
+	if err != nil {
+		return "", false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secret, ok := s.secrets[name]
+	if !ok {
+		secret, err = s.access(name)
+		if err != nil {
+			return "", false, err
+		}
+		s.secrets[name] = secret
+	}
+	if secret == nil {
+		return "", false, nil
+	}
+	return *secret, true, nil
+}
+
+// expands a gsm tag to the full resource name of a secret version
+func (s *GSMSource) resourceName(key string) (string, error) {
+	if !strings.HasPrefix(key, "projects/") {
+		if s.project == "" {
+			return "", errGSMNoProject
+		}
+		key = "projects/" + s.project + "/secrets/" + key
+	}
+	if !strings.Contains(key, "/versions/") {
+		key += "/versions/" + latestGSMVersion
+	}
+	return key, nil
+}
+
+// accesses a secret version, returning nil when it does not exist
+func (s *GSMSource) access(name string) (*string, error) {
+	resp, err := s.client.Get(s.endpoint + "/v1/" + name + ":access")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", errGSMStatus, resp.Status)
+	}
+	var output struct {
+		Payload struct {
+			Data       string `json:"data"`
+			DataCrc32c string `json:"dataCrc32c"`
+		} `json:"payload"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&output)
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(output.Payload.Data)
+	if err != nil {
+		return nil, err
+	}
+	if output.Payload.DataCrc32c != "" {
+		checksum, err := strconv.ParseUint(output.Payload.DataCrc32c, 10, 32)
+		if err != nil || uint32(checksum) != crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)) {
+			return nil, errGSMChecksum
+		}
+	}
+	secret := string(data)
+	return &secret, nil
+}
@@ -0,0 +1,154 @@
+package environ_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"hash/crc32"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/NeedMoreVolume/environ"
+)
+
+// fakeGSM mimics the access endpoint of the Secret Manager REST API
+type fakeGSM struct {
+	mu sync.Mutex
+	// secrets by full version resource name
+	secrets map[string]string
+	calls   int
+	corrupt bool
+}
+
+func (f *fakeGSM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, found := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/v1/"), ":access")
+	if r.Method != http.MethodGet || !found {
+		http.Error(w, "unsupported method", http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+	secret, ok := f.secrets[name]
+	if !ok {
+		http.Error(w, `{"error":{"code":404,"status":"NOT_FOUND"}}`, http.StatusNotFound)
+		return
+	}
+	checksum := crc32.Checksum([]byte(secret), crc32.MakeTable(crc32.Castagnoli))
+	if f.corrupt {
+		checksum++
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"name": name,
+		"payload": map[string]string{
+			"data":       base64.StdEncoding.EncodeToString([]byte(secret)),
+			"dataCrc32c": strconv.FormatUint(uint64(checksum), 10),
+		},
+	})
+}
+
+type exampleGSMConfig struct {
+	Password    string `gsm:"projects/shared/secrets/db-password/versions/latest" required:"true"`
+	OldPassword string `gsm:"projects/shared/secrets/db-password/versions/1"`
+	Token       string `gsm:"api-token"`
+	PinnedToken string `gsm:"api-token/versions/2"`
+	SharedToken string `gsm:"projects/shared/secrets/api-token"`
+	Missing     string `gsm:"missing" default:"fallback"`
+}
+
+func TestGSMSource(t *testing.T) {
+	fake := &fakeGSM{
+		secrets: map[string]string{
+			"projects/shared/secrets/db-password/versions/latest": "hunter2",
+			"projects/shared/secrets/db-password/versions/1":      "hunter1",
+			"projects/shared/secrets/api-token/versions/latest":   "shared token",
+			"projects/local/secrets/api-token/versions/latest":    "local token",
+			"projects/local/secrets/api-token/versions/2":         "pinned token",
+		},
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	var (
+		source = environ.NewGSMSource(server.Client(), "local", server.URL)
+		cfg    exampleGSMConfig
+	)
+	err := environ.LoadWith(&cfg, source)
+	if err != nil {
+		slog.Error("failed to load gsm config", "error", err)
+		t.FailNow()
+	}
+	expected := exampleGSMConfig{
+		Password:    "hunter2",
+		OldPassword: "hunter1",
+		Token:       "local token",
+		PinnedToken: "pinned token",
+		SharedToken: "shared token",
+		Missing:     "fallback",
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		slog.Error("expected result does not match result", "expected result", expected, "result", cfg)
+		t.Fail()
+	}
+
+	// versions are cached within a load but refreshed on the next one
+	_, _, _ = source.Lookup("api-token")
+	if fake.calls != 6 {
+		slog.Error("secrets were not cached", "calls", fake.calls)
+		t.Fail()
+	}
+	err = environ.LoadWith(&cfg, source)
+	if err != nil || fake.calls != 12 {
+		slog.Error("secrets were not refreshed", "calls", fake.calls, "error", err)
+		t.Fail()
+	}
+}
+
+func TestGSMSourceErrors(t *testing.T) {
+	fake := &fakeGSM{
+		secrets: map[string]string{
+			"projects/local/secrets/api-token/versions/latest": "local token",
+		},
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	corrupt := httptest.NewServer(&fakeGSM{secrets: fake.secrets, corrupt: true})
+	defer corrupt.Close()
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer unavailable.Close()
+
+	testCases := map[string]struct {
+		source *environ.GSMSource
+		key    string
+	}{
+		"short form without a default project": {
+			source: environ.NewGSMSource(server.Client(), "", server.URL),
+			key:    "api-token",
+		},
+		"unexpected response": {
+			source: environ.NewGSMSource(unavailable.Client(), "local", unavailable.URL),
+			key:    "api-token",
+		},
+		"payload checksum mismatch": {
+			source: environ.NewGSMSource(corrupt.Client(), "local", corrupt.URL),
+			key:    "api-token",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := tc.source.Lookup(tc.key)
+			if err == nil {
+				slog.Error("expected an error looking up secret")
+				t.Fail()
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+package environ
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// indexSeparator joins the parts of indexed env keys, IE: BACKENDS_0_HOST
+const indexSeparator = "_"
+
+// checks if a type is a slice or map of structs, or of pointers to structs, that is loaded from indexed env keys
+func isStructCollection(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Map {
+		return false
+	}
+	elem := t.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	return elem.Kind() == reflect.Struct && !isDecodable(elem)
+}
+
+// elementSource is implemented by sources that hold slices and maps of structs under a single key, such as arrays of
+// objects in config files. elements returns the indices of the array or the keys of the object at key in order, with
+// a source that looks up the fields of each element relative to the element.
+type elementSource interface {
+	Source
+	elements(key string) ([]string, map[string]Source, error)
+}
+
+// wraps handling a slice or map of structs. Elements are discovered by listing the keys of the env sources, where a
+// slice is loaded from indexed keys, IE: BACKENDS_0_HOST and BACKENDS_1_HOST for `env:"BACKENDS"`, and a map is
+// loaded from keyed keys, IE: TENANTS_ACME_QUOTA for `env:"TENANTS"`. Sources that list elements, such as config
+// files, add their elements, and each element is loaded from every source that has it. Returns if any element was
+// loaded.
+func (l *loader) handleStructCollection(input reflect.Value, structField reflect.StructField, sc scope) (bool, error) {
+	var (
+		inputType = input.Type()
+		elemType  = inputType.Elem()
+		isPtr     = elemType.Kind() == reflect.Ptr
+		names     []string
+		// the sources of each element, by the index of the source that listed them
+		elements = map[int]map[string]Source{}
+	)
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	var (
+		collectionKeys = l.collectionKeys(structField, sc)
+		hasEnv         = len(collectionKeys) > 0
+		base           string
+	)
+	if hasEnv {
+		base = collectionKeys[0] + indexSeparator
+	}
+	// elements are discovered under the first key that has any, so fallback keys still load while they are replaced
+	for i, collectionKey := range collectionKeys {
+		keyBase := collectionKey + indexSeparator
+		keys, err := l.listEnvKeys(keyBase)
+		if err != nil {
+			return false, newLoadingError(structField.Name, envTag, "failed to list keys from env source", err)
+		}
+		if inputType.Kind() == reflect.Slice {
+			names = sliceIndices(keys, keyBase)
+		} else {
+			names = mapKeys(keys, keyBase, relativeEnvKeys(elemType, "", nil, l.naming, map[reflect.Type]bool{}))
+		}
+		if len(names) == 0 {
+			continue
+		}
+		base = keyBase
+		// the elements came from a fallback key
+		if i > 0 && l.deprecation != nil {
+			l.deprecation(strings.Join(sc.fieldPath(structField.Name), "."), collectionKey, collectionKeys[0])
+		}
+		break
+	}
+	for i, source := range l.sources {
+		lister, ok := source.(elementSource)
+		if !ok {
+			continue
+		}
+		key, ok := l.fieldKey(source, structField, sc)
+		if !ok {
+			continue
+		}
+		sourceNames, sources, err := lister.elements(key)
+		if err != nil {
+			return false, newLoadingError(structField.Name, source.Tag(), "failed to list elements from "+source.Tag()+" source", err)
+		}
+		for _, name := range sourceNames {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+		elements[i] = sources
+	}
+	if len(names) == 0 {
+		return false, nil
+	}
+
+	// elements are loaded into new structs with the indexed key as their prefix, from the sources that have them
+	newElem := func(name string) reflect.Value {
+		sources := l.sources
+		defer func() {
+			l.sources = sources
+		}()
+		l.sources = make([]Source, 0, len(sources))
+		for i, source := range sources {
+			if _, ok := source.(elementSource); ok {
+				if element, found := elements[i][name]; found {
+					l.sources = append(l.sources, element)
+				}
+				continue
+			}
+			// env keys of elements only exist under the env key of the collection
+			if source.Tag() == envTag && !hasEnv {
+				continue
+			}
+			l.sources = append(l.sources, source)
+		}
+		path := append(sc.fieldPath(structField.Name), name)
+		elem := reflect.New(elemType)
+		l.handleStruct(elem.Elem(), scope{
+			prefix:       base + name + indexSeparator,
+			path:         path,
+			elementDepth: len(path),
+		})
+		if isPtr {
+			return elem
+		}
+		return elem.Elem()
+	}
+	switch inputType.Kind() {
+	case reflect.Slice:
+		sortIndices(names)
+		slice := reflect.MakeSlice(inputType, len(names), len(names))
+		for i, name := range names {
+			slice.Index(i).Set(newElem(name))
+		}
+		input.Set(slice)
+		return true, nil
+	case reflect.Map:
+		sort.Strings(names)
+		m := reflect.MakeMapWithSize(inputType, len(names))
+		for _, name := range names {
+			key := reflect.New(inputType.Key()).Elem()
+			err := setValue(structField, key, name)
+			if err != nil {
+				return false, err
+			}
+			m.SetMapIndex(key, newElem(name))
+		}
+		input.Set(m)
+		return true, nil
+	}
+	return false, nil
+}
+
+// returns the env keys of a slice or map of structs with their prefix, IE: BACKENDS for `env:"BACKENDS"`, followed by
+// any fallback keys in the tag. Returns nothing when the field has no env key.
+func (l *loader) collectionKeys(structField reflect.StructField, sc scope) []string {
+	tag, found := structField.Tag.Lookup(envTag)
+	if !found {
+		if l.naming == nil {
+			return nil
+		}
+		tag = l.naming(sc.fieldNames(structField.Name))
+	}
+	keys := envKeys(tag)
+	for i := range keys {
+		keys[i] = sc.prefix + keys[i]
+	}
+	return keys
+}
+
+// lists the keys starting with the prefix from every env source that can list its keys
+func (l *loader) listEnvKeys(prefix string) ([]string, error) {
+	var keys []string
+	for _, source := range l.sources {
+		lister, ok := source.(Lister)
+		if !ok || source.Tag() != envTag {
+			continue
+		}
+		sourceKeys, err := lister.Keys()
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range sourceKeys {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// finds the unique indices used in keys like <base><index>_<key>, in numeric order
+func sliceIndices(keys []string, base string) []string {
+	var indices []string
+	for _, key := range keys {
+		index, _, found := strings.Cut(strings.TrimPrefix(key, base), indexSeparator)
+		if _, err := strconv.ParseUint(index, 10, 64); !found || err != nil || slices.Contains(indices, index) {
+			continue
+		}
+		indices = append(indices, index)
+	}
+	sortIndices(indices)
+	return indices
+}
+
+// sorts slice indices in numeric order
+func sortIndices(indices []string) {
+	sort.Slice(indices, func(i, j int) bool {
+		a, _ := strconv.ParseUint(indices[i], 10, 64)
+		b, _ := strconv.ParseUint(indices[j], 10, 64)
+		return a < b
+	})
+}
+
+// finds the unique map keys used in keys like <base><map key>_<relative key>, where relative keys are the env keys of
+// the map's struct. The longest relative key that matches is used, so a map key can contain the index separator.
+func mapKeys(keys []string, base string, relativeKeys []string) []string {
+	sort.Slice(relativeKeys, func(i, j int) bool {
+		return len(relativeKeys[i]) > len(relativeKeys[j])
+	})
+	var found []string
+	for _, key := range keys {
+		rest := strings.TrimPrefix(key, base)
+		for _, relativeKey := range relativeKeys {
+			mapKey, ok := strings.CutSuffix(rest, indexSeparator+relativeKey)
+			if !ok || mapKey == "" {
+				continue
+			}
+			if !slices.Contains(found, mapKey) {
+				found = append(found, mapKey)
+			}
+			break
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
+// lists the env keys of a struct type relative to the struct, including the prefixed keys of nested structs. Keys of
+// fields without an env tag are derived from their names when there is a naming strategy.
+func relativeEnvKeys(structType reflect.Type, prefix string, names []string, naming NamingStrategy, walking map[reflect.Type]bool) []string {
+	// recursive types are not walked again within themselves
+	if walking[structType] {
+		return nil
+	}
+	walking[structType] = true
+	defer delete(walking, structType)
+	var keys []string
+	for i := 0; i < structType.NumField(); i++ {
+		structField := structType.Field(i)
+		fieldType := structField.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() == reflect.Struct && !isDecodable(fieldType) {
+			nestedNames := append(slices.Clip(names), structField.Name)
+			if _, found := structField.Tag.Lookup(envPrefixTag); found || structField.Anonymous {
+				nestedNames = names
+			}
+			keys = append(keys, relativeEnvKeys(fieldType, prefix+structField.Tag.Get(envPrefixTag), nestedNames, naming, walking)...)
+			continue
+		}
+		if tag, ok := structField.Tag.Lookup(envTag); ok {
+			for _, key := range envKeys(tag) {
+				keys = append(keys, prefix+key)
+			}
+		} else if naming != nil {
+			keys = append(keys, prefix+naming(append(slices.Clip(names), structField.Name)))
+		}
+	}
+	return keys
+}
@@ -0,0 +1,155 @@
+package environ_test
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/NeedMoreVolume/environ"
+)
+
+type exampleBackend struct {
+	Host string `env:"HOST" required:"true"`
+	Port int    `env:"PORT" default:"80"`
+}
+
+type exampleTenant struct {
+	Quota  int `env:"QUOTA"`
+	Limits struct {
+		Max int `env:"MAX"`
+	} `envPrefix:"LIMITS_"`
+}
+
+type exampleRecursiveTenant struct {
+	Quota  int                     `env:"QUOTA"`
+	Parent *exampleRecursiveTenant `envPrefix:"PARENT_"`
+}
+
+type exampleIndexedConfig struct {
+	Backends     []exampleBackend          `env:"BACKENDS"`
+	BackendPtrs  []*exampleBackend         `env:"BACKEND_PTRS"`
+	Tenants      map[string]exampleTenant  `env:"TENANTS"`
+	TenantPtrs   map[string]*exampleTenant `env:"TENANT_PTRS"`
+	Untagged     []exampleBackend
+	Unconfigured []exampleBackend                  `env:"UNCONFIGURED"`
+	Recursive    map[string]exampleRecursiveTenant `env:"RECURSIVE"`
+}
+
+func TestLoadIndexed(t *testing.T) {
+	testCases := map[string]struct {
+		env            map[string]string
+		opts           []environ.Option
+		expectedResult exampleIndexedConfig
+		expectedError  environ.EnvError
+	}{
+		"slices and maps of structs": {
+			env: map[string]string{
+				"BACKENDS_0_HOST":           "a.internal",
+				"BACKENDS_0_PORT":           "8080",
+				"BACKENDS_10_HOST":          "c.internal",
+				"BACKENDS_2_HOST":           "b.internal",
+				"BACKENDS_X_HOST":           "ignored.internal",
+				"BACKEND_PTRS_0_HOST":       "ptr.internal",
+				"TENANTS_ACME_QUOTA":        "10",
+				"TENANTS_ACME_LIMITS_MAX":   "20",
+				"TENANTS_GLOBEX_CORP_QUOTA": "30",
+				"TENANTS_UNKNOWN_FIELD":     "ignored",
+				"TENANT_PTRS_ACME_QUOTA":    "40",
+			},
+			expectedResult: exampleIndexedConfig{
+				Backends: []exampleBackend{
+					{Host: "a.internal", Port: 8080},
+					{Host: "b.internal", Port: 80},
+					{Host: "c.internal", Port: 80},
+				},
+				BackendPtrs: []*exampleBackend{
+					{Host: "ptr.internal", Port: 80},
+				},
+				Tenants: map[string]exampleTenant{
+					"ACME": {Quota: 10, Limits: struct {
+						Max int `env:"MAX"`
+					}{Max: 20}},
+					"GLOBEX_CORP": {Quota: 30},
+				},
+				TenantPtrs: map[string]*exampleTenant{
+					"ACME": {Quota: 40},
+				},
+			},
+		},
+		"with a global prefix": {
+			env: map[string]string{
+				"APP_BACKENDS_0_HOST":    "a.internal",
+				"APP_TENANTS_ACME_QUOTA": "10",
+				"BACKENDS_1_HOST":        "ignored.internal",
+			},
+			opts: []environ.Option{environ.WithPrefix("APP_")},
+			expectedResult: exampleIndexedConfig{
+				Backends: []exampleBackend{
+					{Host: "a.internal", Port: 80},
+				},
+				Tenants: map[string]exampleTenant{
+					"ACME": {Quota: 10},
+				},
+			},
+		},
+		"map of a recursive struct": {
+			env: map[string]string{
+				"RECURSIVE_ACME_QUOTA": "3",
+			},
+			expectedResult: exampleIndexedConfig{
+				Recursive: map[string]exampleRecursiveTenant{
+					"ACME": {Quota: 3},
+				},
+			},
+		},
+		"required field of an element": {
+			env: map[string]string{
+				"BACKENDS_0_PORT": "8080",
+			},
+			expectedError: environ.EnvError{
+				Err:    environ.ErrRequiredNotFound,
+				Key:    "Host",
+				Source: "env",
+				Extra:  "required field not loaded",
+			},
+		},
+		"bad value in an element": {
+			env: map[string]string{
+				"BACKENDS_0_HOST": "a.internal",
+				"BACKENDS_0_PORT": "not a port",
+			},
+			expectedError: environ.EnvError{
+				Err:    environ.ErrInvalidFormat,
+				Key:    "Port",
+				Source: "env",
+				Extra:  "value is not a valid integer representation",
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			var cfg exampleIndexedConfig
+			err := environ.Load(&cfg, tc.opts...)
+			var envErr *environ.EnvError
+			if errors.As(err, &envErr) {
+				if tc.expectedError != *envErr {
+					slog.Error("expected error didn't match error", "expected error", tc.expectedError, "error", *envErr)
+					t.Fail()
+				}
+				return
+			}
+			if tc.expectedError.Err != nil {
+				slog.Error("no error occured where an error was expected", "expected error", tc.expectedError)
+				t.FailNow()
+			}
+			if !reflect.DeepEqual(cfg, tc.expectedResult) {
+				slog.Error("expected result does not match result", "expected result", tc.expectedResult, "result", cfg)
+				t.Fail()
+			}
+		})
+	}
+}
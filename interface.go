@@ -0,0 +1,110 @@
+package environ
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// implementations holds the types registered for interfaces, by interface type and name
+var implementations = struct {
+	sync.RWMutex
+	types map[reflect.Type]map[string]reflect.Type
+}{
+	types: map[reflect.Type]map[string]reflect.Type{},
+}
+
+// RegisterType registers T as an implementation of the interface I under a name. Interface fields of type I with a
+// `type` tag are loaded by reading the key in the tag to get a name, then loading a new T selected by that name, IE:
+// with RegisterType[Cache, *RedisCacheConfig]("redis"), a field tagged `type:"CACHE_KIND"` is loaded as a
+// *RedisCacheConfig when CACHE_KIND=redis. T must be a struct or a pointer to a struct that implements I.
+func RegisterType[I any, T any](name string) {
+	var (
+		iface = reflect.TypeOf((*I)(nil)).Elem()
+		impl  = reflect.TypeOf((*T)(nil)).Elem()
+	)
+	if iface.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("environ: RegisterType: %s is not an interface", iface))
+	}
+	if !impl.Implements(iface) {
+		panic(fmt.Sprintf("environ: RegisterType: %s does not implement %s", impl, iface))
+	}
+	if structType(impl) == nil {
+		panic(fmt.Sprintf("environ: RegisterType: %s is not a struct or a pointer to a struct", impl))
+	}
+	implementations.Lock()
+	defer implementations.Unlock()
+	if implementations.types[iface] == nil {
+		implementations.types[iface] = map[string]reflect.Type{}
+	}
+	implementations.types[iface][name] = impl
+}
+
+// returns the type registered for an interface under a name
+func getImplementation(iface reflect.Type, name string) (reflect.Type, bool) {
+	implementations.RLock()
+	defer implementations.RUnlock()
+	impl, ok := implementations.types[iface][name]
+	return impl, ok
+}
+
+// returns the struct types registered for an interface, sorted by name
+func getImplementations(iface reflect.Type) []reflect.Type {
+	implementations.RLock()
+	defer implementations.RUnlock()
+	names := make([]string, 0, len(implementations.types[iface]))
+	for name := range implementations.types[iface] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	types := make([]reflect.Type, 0, len(names))
+	for _, name := range names {
+		types = append(types, structType(implementations.types[iface][name]))
+	}
+	return types
+}
+
+// returns the struct type of a struct or pointer to a struct, or nil for other types
+func structType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	return t
+}
+
+// wraps handling an interface field with a type tag. The name of the implementation is read from the env key in the
+// type tag, falling back to the default tag of the field, then a new value of the implementation registered under
+// that name is loaded with the scope of a nested struct. Returns if the name or any field of the implementation was
+// loaded.
+func (l *loader) handleInterface(input reflect.Value, structField reflect.StructField, sc scope) (bool, error) {
+	// the name is read like a field tagged with the env key, keeping the default and required tags of the field. Only
+	// env sources hold the key, so sources that derive keys do not read the implementation's fields as the name.
+	tag := fmt.Sprintf("%s:%q %s:%q", envTag, structField.Tag.Get(typeTag), sourcesTag, envTag+","+defaultTag)
+	for _, key := range []string{defaultTag, requiredTag} {
+		if v, found := structField.Tag.Lookup(key); found {
+			tag += fmt.Sprintf(" %s:%q", key, v)
+		}
+	}
+	nameField := structField
+	nameField.Tag = reflect.StructTag(tag)
+	name, source, _, err := l.getValue(nameField, sc)
+	if err != nil || name == "" {
+		return false, err
+	}
+	impl, ok := getImplementation(input.Type(), name)
+	if !ok {
+		return false, newSourceError(ErrInvalidFormat, structField.Name, source, "value is not the name of a registered type")
+	}
+	value := reflect.New(structType(impl))
+	loaded := l.handleStruct(value.Elem(), sc.nested(structField))
+	if impl.Kind() == reflect.Ptr {
+		input.Set(value)
+	} else {
+		input.Set(value.Elem())
+	}
+	return loaded || source != defaultTag, nil
+}
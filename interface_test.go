@@ -0,0 +1,139 @@
+package environ_test
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/NeedMoreVolume/environ"
+)
+
+type exampleCache interface {
+	Kind() string
+}
+
+type exampleRedisCacheConfig struct {
+	Addr string `env:"REDIS_ADDR" required:"true"`
+	DB   int    `env:"REDIS_DB"`
+}
+
+func (*exampleRedisCacheConfig) Kind() string { return "redis" }
+
+type exampleMemoryCacheConfig struct {
+	TTL time.Duration `env:"MEMORY_TTL" default:"1m"`
+}
+
+func (exampleMemoryCacheConfig) Kind() string { return "memory" }
+
+type exampleInterfaceConfig struct {
+	Cache    exampleCache `type:"CACHE_KIND" envPrefix:"CACHE_" default:"memory"`
+	Fallback exampleCache `type:"FALLBACK_KIND"`
+}
+
+func init() {
+	environ.RegisterType[exampleCache, *exampleRedisCacheConfig]("redis")
+	environ.RegisterType[exampleCache, exampleMemoryCacheConfig]("memory")
+}
+
+func TestLoadInterfaces(t *testing.T) {
+	configSource, err := environ.NewJSONFileSource(writeConfigFile(t, "config.json", `{"cache": {"ttl": "5m"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := map[string]struct {
+		env           map[string]string
+		opts          []environ.Option
+		expected      exampleInterfaceConfig
+		expectedError *environ.EnvError
+	}{
+		"default type": {
+			expected: exampleInterfaceConfig{
+				Cache: exampleMemoryCacheConfig{TTL: time.Minute},
+			},
+		},
+		"selected type": {
+			env: map[string]string{
+				"CACHE_KIND":       "redis",
+				"CACHE_REDIS_ADDR": "redis:6379",
+				"FALLBACK_KIND":    "memory",
+				"MEMORY_TTL":       "5m",
+			},
+			expected: exampleInterfaceConfig{
+				Cache:    &exampleRedisCacheConfig{Addr: "redis:6379"},
+				Fallback: exampleMemoryCacheConfig{TTL: 5 * time.Minute},
+			},
+		},
+		"selected type in strict mode": {
+			env: map[string]string{
+				"CACHE_KIND":       "redis",
+				"CACHE_REDIS_ADDR": "redis:6379",
+			},
+			opts: []environ.Option{environ.WithStrict()},
+			expected: exampleInterfaceConfig{
+				Cache: &exampleRedisCacheConfig{Addr: "redis:6379"},
+			},
+		},
+		"sources that derive keys are not read for the name": {
+			opts: []environ.Option{environ.WithSources(environ.EnvSource(), configSource)},
+			expected: exampleInterfaceConfig{
+				Cache: exampleMemoryCacheConfig{TTL: 5 * time.Minute},
+			},
+		},
+		"selected type missing required field": {
+			env: map[string]string{
+				"CACHE_KIND": "redis",
+			},
+			expectedError: &environ.EnvError{Err: environ.ErrRequiredNotFound, Key: "Addr", Source: "env", Extra: "required field not loaded"},
+		},
+		"unknown type": {
+			env: map[string]string{
+				"CACHE_KIND": "memcached",
+			},
+			expectedError: &environ.EnvError{Err: environ.ErrInvalidFormat, Key: "Cache", Source: "env", Extra: "value is not the name of a registered type"},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			var (
+				cfg    exampleInterfaceConfig
+				envErr *environ.EnvError
+			)
+			err := environ.Load(&cfg, tc.opts...)
+			if tc.expectedError != nil {
+				if !errors.As(err, &envErr) || !reflect.DeepEqual(envErr, tc.expectedError) {
+					slog.Error("expected error didn't match error", "expected error", tc.expectedError, "error", err)
+					t.Fail()
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(cfg, tc.expected) {
+				slog.Error("expected result does not match result", "expected result", tc.expected, "result", cfg, "error", err)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestRegisterTypePanics(t *testing.T) {
+	testCases := map[string]func(){
+		"not an interface": func() { environ.RegisterType[exampleRedisCacheConfig, exampleRedisCacheConfig]("redis") },
+		"not implemented":  func() { environ.RegisterType[exampleCache, exampleRedisCacheConfig]("redis") },
+		"not a struct":     func() { environ.RegisterType[any, string]("string") },
+	}
+	for name, register := range testCases {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					slog.Error("expected registering the type to panic")
+					t.Fail()
+				}
+			}()
+			register()
+		})
+	}
+}
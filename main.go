@@ -1,8 +1,9 @@
 package environ
 
 import (
-	"os"
+	"errors"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -17,32 +18,203 @@ const (
 	gsmTag      = "gsm"      // used to get value from GCP Secrets, string
 	swiftTag    = "swift"    // used to get value from Swift based storage
 	requiredTag = "required" // used to set requirements for env params, bool: causes errors when not loaded
+	sourcesTag  = "sources"  // used to override the order sources are checked in for a field, comma separated tags
+	configTag   = "config"   // used to get value from config files, key path separated by dots
+	fileTag     = "file"     // used to get value from the contents of a file, path
+	flagTag     = "flag"     // used to get value from a command-line flag, flag name
+
+	// nesting tags
+	envPrefixTag = "envPrefix" // used to prefix the env keys of every field in a nested struct, string
+	typeTag      = "type"      // used to select the registered type of an interface field by the name in an env key, string
+
+	// validation tags
+	minTag    = "min"    // used to set the minimum of a number or duration, number or duration
+	maxTag    = "max"    // used to set the maximum of a number or duration, number or duration
+	lenTag    = "len"    // used to set the exact length of a string, slice or map, int
+	minLenTag = "minlen" // used to set the minimum length of a string, slice or map, int
+	maxLenTag = "maxlen" // used to set the maximum length of a string, slice or map, int
+	oneofTag  = "oneof"  // used to set the allowed values of a field, comma separated
+	regexTag  = "regex"  // used to set a pattern strings must match, regular expression
+	formatTag = "format" // used to set the format of a string, one of url, hostport or email
+
+	// conditional tags
+	requiredIfTag = "required_if" // used to require a field when another field of the struct has a value, Field=value
+
+	// usage tags
+	descTag   = "desc"   // used to describe a field in the usage of its flag, string
+	secretTag = "secret" // used to flag a field as secret so Redact replaces it, bool
 
 	// formatting tags
 	separatorTag   = "separator"    // used to select custom separators for slices and map items
 	kvSeparatorTag = "kv_separator" // used to select custom separators for key value pairs in maps
+	layoutTag      = "layout"       // used to select the layout times are parsed with, layout, layout name, unix or unixmilli
 
 	// defaults
 	defaultSeparator   = ","
 	defaultKvSeparator = ":"
 
+	// brackets used to wrap nested collections, IE: a:[1,2],b:[3]
+	openBracket  = '['
+	closeBracket = ']'
+
 	// misc helpers
 	durationUnits = "smh"
+
+	// env keys
+	skipKey         = "-" // used as the env tag of fields that are never loaded
+	envKeySeparator = "," // separates the fallback keys in env tags
 )
 
-// Load fills the config with values based on tags provided on the struct
-func Load(config any) error {
+// Load fills the config with values based on tags provided on the struct. Values are read from the environment unless
+// other sources are provided with the WithSources option, and from the files named by file tags. Every field is
+// loaded even when some fail, and the errors for all of the fields that failed are returned together in a MultiError.
+// Sources that fail to preload are reported in a MultiError as well, and no fields are loaded.
+func Load(config any, opts ...Option) error {
 	configStruct, err := validateConfig(config)
 	if err != nil {
 		return err
 	}
-	err = handleStruct(configStruct)
-	if err != nil {
-		return err
+	l := &loader{
+		sources:        []Source{EnvSource()},
+		allocating:     map[reflect.Type]bool{},
+		validationErrs: map[*EnvError]bool{},
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	// sources that are placed relative to other sources are added once the sources are set
+	l.addDotenvSources()
+	if l.flags != nil {
+		l.sources = append([]Source{l.flags}, l.sources...)
+	}
+	// file tags are checked after the other sources and the default tag is the final fallback, unless they have been
+	// given a place in the order
+	if !l.hasSource(fileTag) {
+		l.sources = append(l.sources, FileSource())
+	}
+	if !l.hasSource(defaultTag) {
+		l.sources = append(l.sources, DefaultSource())
+	}
+	// fields are not loaded from sources that failed to preload
+	l.preload(configStruct.Type())
+	if len(l.errs) == 0 {
+		l.handleStruct(configStruct, scope{prefix: l.prefix})
+	}
+	if len(l.errs) > 0 {
+		return &MultiError{Errors: l.errs}
 	}
 	return nil
 }
 
+// LoadWith fills the config with values based on tags provided on the struct, reading from the given sources.
+// Sources are checked in order and the first one to find a value for a field wins, falling back to the default
+// tag when none of them do.
+func LoadWith(config any, sources ...Source) error {
+	return Load(config, WithSources(sources...))
+}
+
+// loader holds the state for a single load of a config
+type loader struct {
+	sources    []Source
+	prefix     string
+	errs       []*EnvError
+	allocating map[reflect.Type]bool
+	// errors returned by Validate methods, which are dropped with the struct when it is not configured
+	validationErrs map[*EnvError]bool
+	// skipUnexported skips unexported fields instead of reporting them
+	skipUnexported bool
+	// strict reports fields that have no tag for any source
+	strict bool
+	// naming derives the env keys of fields without an env tag
+	naming NamingStrategy
+	// deprecation is called when a value is loaded from a fallback env key
+	deprecation DeprecationFunc
+	// dotenv is the dotenv sources to add next to the env source
+	dotenv []dotenvOption
+	// flags is the flag source to add before every other source
+	flags *FlagSource
+}
+
+// checks if any source is bound to the tag
+func (l *loader) hasSource(tag string) bool {
+	for _, source := range l.sources {
+		if source.Tag() == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// checks if a field is tagged for any source of the load. Nested structs, pointers to structs and interfaces with a
+// type tag are loaded through their fields and always count as tagged, while slices and maps of structs need an env key or a source that lists
+// their elements.
+func (l *loader) hasTags(fieldType reflect.Type, structField reflect.StructField) bool {
+	if isStructCollection(fieldType) {
+		_, found := structField.Tag.Lookup(envTag)
+		for _, source := range l.fieldSources(structField) {
+			if _, ok := source.(elementSource); ok && l.hasTag(source, structField) {
+				found = true
+			}
+		}
+		return found || l.naming != nil
+	}
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType.Kind() == reflect.Struct && !isDecodable(fieldType) {
+		return true
+	}
+	// interfaces are loaded through the registered type selected by their type tag
+	if fieldType.Kind() == reflect.Interface && structField.Tag.Get(typeTag) != "" {
+		return true
+	}
+	for _, source := range l.fieldSources(structField) {
+		if l.hasTag(source, structField) {
+			return true
+		}
+	}
+	return false
+}
+
+// returns the sources to check for a field in order, following the sources tag when the field has one
+func (l *loader) fieldSources(structField reflect.StructField) []Source {
+	order, found := structField.Tag.Lookup(sourcesTag)
+	if !found {
+		return l.sources
+	}
+	var (
+		tags    = strings.Split(order, ",")
+		sources = make([]Source, 0, len(tags)+1)
+	)
+	for _, tag := range tags {
+		for _, source := range l.sources {
+			// sources that are not part of this load are skipped
+			if source.Tag() == strings.TrimSpace(tag) {
+				sources = append(sources, source)
+			}
+		}
+	}
+	if !slices.ContainsFunc(sources, func(source Source) bool { return source.Tag() == defaultTag }) {
+		sources = append(sources, DefaultSource())
+	}
+	return sources
+}
+
+// gives sources that support bulk fetching every key they will be asked for, recording an error for each source that
+// fails
+func (l *loader) preload(configType reflect.Type) {
+	for _, source := range l.sources {
+		preloader, ok := source.(Preloader)
+		if !ok {
+			continue
+		}
+		err := preloader.Preload(collectKeys(configType, source.Tag()))
+		if err != nil {
+			l.addError(newLoadingError(source.Tag(), source.Tag(), "failed to preload values from source", err))
+		}
+	}
+}
+
 // validates that a config is a pointer to a struct
 func validateConfig(config any) (reflect.Value, error) {
 	var output reflect.Value
@@ -57,83 +229,357 @@ func validateConfig(config any) (reflect.Value, error) {
 	return output, nil
 }
 
-// wraps handling fields of a struct
-func handleStruct(input reflect.Value) error {
+// scope is the position of a struct within the config
+type scope struct {
+	// prefix is added to the env keys of every field
+	prefix string
+	// path is the names of the fields leading to the struct
+	path []string
+	// names is the names of the fields leading to the struct since the last prefix, used to derive env keys
+	names []string
+	// embedded is set for embedded structs, whose methods are promoted to the struct embedding them
+	embedded bool
+	// elementDepth is the length of the path at the element of a slice or map of structs, sources derive the keys of
+	// the fields of an element from the path after it
+	elementDepth int
+}
+
+// returns the scope of a nested struct field, extending the prefix with its envPrefix tag. The name of the field is
+// used to derive env keys unless it has an envPrefix tag, which replaces it.
+func (s scope) nested(structField reflect.StructField) scope {
+	sc := scope{
+		prefix:       s.prefix + structField.Tag.Get(envPrefixTag),
+		path:         s.fieldPath(structField.Name),
+		elementDepth: s.elementDepth,
+	}
+	if _, found := structField.Tag.Lookup(envPrefixTag); !found {
+		sc.names = s.fieldNames(structField.Name)
+	}
+	return sc
+}
+
+// returns the scope of an embedded struct field, extending the prefix with its envPrefix tag. The path is not extended
+// since the fields of an embedded struct are promoted to the struct embedding it.
+func (s scope) embed(structField reflect.StructField) scope {
+	return scope{
+		prefix:       s.prefix + structField.Tag.Get(envPrefixTag),
+		path:         s.path,
+		names:        s.names,
+		embedded:     true,
+		elementDepth: s.elementDepth,
+	}
+}
+
+// returns the path of a field in the scope
+func (s scope) fieldPath(name string) []string {
+	return append(slices.Clip(s.path), name)
+}
+
+// returns the names used to derive the env key of a field in the scope
+func (s scope) fieldNames(name string) []string {
+	return append(slices.Clip(s.names), name)
+}
+
+// wraps handling fields of a struct, recording any errors so every field is handled. Once the fields are loaded the
+// struct is validated. Returns if any field was loaded.
+func (l *loader) handleStruct(input reflect.Value, sc scope) bool {
 	var (
-		inputType = input.Type()
-		err       error
+		inputType    = input.Type()
+		loaded       bool
+		loadedFields = map[string]bool{}
+		errs         = len(l.errs)
 	)
 	for i := 0; i < input.NumField(); i++ {
 		var (
 			field       = input.Field(i)
 			structField = inputType.Field(i)
+			fieldLoaded bool
+			err         error
 		)
+		// fields tagged env:"-" are never loaded
+		if structField.Tag.Get(envTag) == skipKey {
+			continue
+		}
+		// embedded structs are flattened into the struct, even when their type is unexported
+		if handled, fieldLoaded := l.handleEmbedded(field, structField, sc); handled {
+			loaded = fieldLoaded || loaded
+			continue
+		}
 		if !field.CanSet() {
-			return newError(ErrUnsettableParam, structField.Name, "")
+			if !l.skipUnexported || structField.IsExported() {
+				l.addError(newError(ErrUnsettableParam, structField.Name, ""))
+			}
+			continue
 		}
-		switch field.Kind() {
-		case reflect.Struct:
-			err = handleStruct(field)
+		switch {
+		case l.strict && !l.hasTags(field.Type(), structField):
+			err = newError(ErrMissingTag, structField.Name, "field has no tag for any source")
+		case isStructCollection(field.Type()):
+			fieldLoaded, err = l.handleStructCollection(field, structField, sc)
+		case field.Kind() == reflect.Struct && !isDecodable(field.Type()):
+			fieldLoaded = l.handleStruct(field, sc.nested(structField))
+		case field.Kind() == reflect.Ptr && field.Type().Elem().Kind() == reflect.Struct && !isDecodable(field.Type().Elem()):
+			fieldLoaded = l.handleStructPtr(field, sc.nested(structField))
+		case field.Kind() == reflect.Interface && structField.Tag.Get(typeTag) != "":
+			fieldLoaded, err = l.handleInterface(field, structField, sc)
 		default:
-			err = handleField(field, structField)
+			fieldLoaded, err = l.handleField(field, structField, sc)
 		}
-		if err != nil {
-			return err
+		l.addError(err)
+		loadedFields[structField.Name] = fieldLoaded
+		loaded = fieldLoaded || loaded
+	}
+	l.validateStruct(input, sc, loadedFields, errs)
+	return loaded
+}
+
+// wraps handling an embedded struct or pointer to a struct, whose fields are loaded as if they were fields of the
+// struct embedding it. Embedded pointers are always allocated so promoted fields can be used. Returns false for
+// fields that are not embedded structs, and if any field was loaded.
+func (l *loader) handleEmbedded(input reflect.Value, structField reflect.StructField, sc scope) (bool, bool) {
+	if !structField.Anonymous {
+		return false, false
+	}
+	t := input.Type()
+	switch {
+	case t.Kind() == reflect.Struct && !isDecodable(t):
+		return true, l.handleStruct(input, sc.embed(structField))
+	case t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct && !isDecodable(t.Elem()):
+		if input.IsNil() {
+			// recursive types are left nil instead of being allocated forever
+			if l.allocating[t] {
+				return true, false
+			}
+			if !input.CanSet() {
+				if !l.skipUnexported {
+					l.addError(newError(ErrUnsettableParam, structField.Name, ""))
+				}
+				return true, false
+			}
+			l.allocating[t] = true
+			defer delete(l.allocating, t)
+			input.Set(reflect.New(t.Elem()))
 		}
+		return true, l.handleStruct(input.Elem(), sc.embed(structField))
 	}
+	return false, false
+}
 
-	return nil
+// wraps handling a pointer to a struct, a nil pointer is only allocated when at least one field of the struct is
+// loaded so it stays nil when the struct is not configured
+func (l *loader) handleStructPtr(input reflect.Value, sc scope) bool {
+	if !input.IsNil() {
+		return l.handleStruct(input.Elem(), sc)
+	}
+	// recursive types are left nil instead of being allocated forever
+	if l.allocating[input.Type()] {
+		return false
+	}
+	l.allocating[input.Type()] = true
+	defer delete(l.allocating, input.Type())
+	var (
+		value  = reflect.New(input.Type().Elem())
+		errs   = len(l.errs)
+		loaded = l.handleStruct(value.Elem(), sc)
+	)
+	if loaded {
+		input.Set(value)
+		return true
+	}
+	// required fields are not missing and validation does not apply when the struct they belong to is not configured
+	kept := l.errs[:errs]
+	for _, err := range l.errs[errs:] {
+		if err.Err != ErrRequiredNotFound && !l.validationErrs[err] {
+			kept = append(kept, err)
+		}
+	}
+	l.errs = kept
+	return false
 }
 
-// wraps reading and setting a param value
-func handleField(input reflect.Value, structField reflect.StructField) error {
-	value, err := getValue(structField)
+// records an error from handling a field
+func (l *loader) addError(err error) {
+	var envErr *EnvError
+	if errors.As(err, &envErr) {
+		l.errs = append(l.errs, envErr)
+	}
+}
+
+// wraps reading and setting a param value, returns if the value was loaded from a source other than the default tag
+func (l *loader) handleField(input reflect.Value, structField reflect.StructField, sc scope) (bool, error) {
+	value, source, node, err := l.getValue(structField, sc)
 	if err != nil {
-		return err
+		return false, err
 	}
 	if value != "" {
-		err = setValue(structField, input, value)
+		if node != nil {
+			err = setNode(structField, input, node)
+		} else {
+			err = setValue(structField, input, value)
+		}
+		if err == nil {
+			err = validateField(structField, input)
+		}
+		var envErr *EnvError
+		if errors.As(err, &envErr) {
+			// report where the bad value came from
+			envErr.Source = source
+		}
 		if err != nil {
-			return err
+			return false, err
 		}
 	}
-	return nil
+	return source != "" && source != defaultTag, nil
 }
 
-// reads value from env/stores based on field tags
-func getValue(structField reflect.StructField) (string, error) {
+// reads value from the sources based on field tags, returning the tag of the source that supplied it. Sources that
+// keep the structure of their values also return the parsed value, so collections can be set without splitting.
+func (l *loader) getValue(structField reflect.StructField, sc scope) (string, string, any, error) {
 	var (
-		value    = structField.Tag.Get(defaultTag)
+		value    string
+		source   string
+		node     any
 		required bool
 		loaded   bool
 		err      error
+		sources  = l.fieldSources(structField)
 	)
 	t, found := structField.Tag.Lookup(requiredTag)
 	if found {
 		required, err = strconv.ParseBool(t)
 		if err != nil {
-			return value, newError(ErrInvalidFormat, structField.Name, "required tag value is not a valid boolean representation")
+			return value, source, node, newError(ErrInvalidFormat, structField.Name, "required tag value is not a valid boolean representation")
 		}
 	}
-	// check env
-	t, found = structField.Tag.Lookup(envTag)
-	if found {
-		v := os.Getenv(t)
-		if v != "" {
-			loaded = true
-			value = v
+	// check sources in order, the first to find a value wins
+	for _, s := range sources {
+		key, found := l.fieldKey(s, structField, sc)
+		if !found {
+			continue
 		}
+		keys := []string{key}
+		if s.Tag() == envTag {
+			keys = envKeys(key)
+			for i := range keys {
+				keys[i] = sc.prefix + keys[i]
+			}
+		}
+		v, index, ok, err := lookupFirst(s, keys)
+		if err != nil {
+			return value, source, node, newLoadingError(structField.Name, s.Tag(), "failed to read value from "+s.Tag()+" source", err)
+		}
+		if !ok {
+			continue
+		}
+		value = v
+		source = s.Tag()
+		// default values are never considered loaded, so required fields keep checking the sources after a default
+		if s.Tag() == defaultTag {
+			if required {
+				continue
+			}
+			break
+		}
+		// the value came from a fallback key
+		if index > 0 && l.deprecation != nil {
+			l.deprecation(strings.Join(sc.fieldPath(structField.Name), "."), keys[index], keys[0])
+		}
+		if nodes, ok := s.(nodeSource); ok {
+			node = nodes.lookupNode(keys[index])
+		}
+		loaded = true
+		break
 	}
 	// check if the field is required but not found/loaded
 	if required && !loaded {
-		return value, newError(ErrRequiredNotFound, structField.Name, "required field not loaded")
+		return value, source, node, newSourceError(ErrRequiredNotFound, structField.Name, l.sourceTags(structField, sources), "required field not loaded")
+	}
+
+	return value, source, node, nil
+}
+
+// returns the key a source looks up for a field, from the tag for the source or derived from the names of the fields
+// leading to it. Returns false when the source cannot look up the field.
+func (l *loader) fieldKey(s Source, structField reflect.StructField, sc scope) (string, bool) {
+	if key, found := structField.Tag.Lookup(s.Tag()); found {
+		return key, true
+	}
+	if deriver, ok := s.(KeyDeriver); ok {
+		return deriver.DeriveKey(sc.fieldPath(structField.Name)[sc.elementDepth:]), true
+	}
+	if s.Tag() == envTag && l.naming != nil {
+		return l.naming(sc.fieldNames(structField.Name)), true
+	}
+	return "", false
+}
+
+// looks up keys in order, returning the value and index of the first key that is found
+func lookupFirst(s Source, keys []string) (string, int, bool, error) {
+	for i, key := range keys {
+		v, ok, err := s.Lookup(key)
+		if err != nil || ok {
+			return v, i, ok, err
+		}
+	}
+	return "", 0, false, nil
+}
+
+// splits an env tag into its keys, env tags can list fallback keys after the preferred key, IE: env:"MYSQL_HOST,DB_HOST"
+func envKeys(tag string) []string {
+	keys := strings.Split(tag, envKeySeparator)
+	for i := range keys {
+		keys[i] = strings.TrimSpace(keys[i])
+	}
+	return keys
+}
+
+// lists the tags of the sources that can supply a loaded value for a field
+func (l *loader) sourceTags(structField reflect.StructField, sources []Source) string {
+	tags := make([]string, 0, len(sources))
+	for _, source := range sources {
+		if source.Tag() != defaultTag && l.hasKey(source, structField) {
+			tags = append(tags, source.Tag())
+		}
 	}
+	return strings.Join(tags, ",")
+}
 
-	return value, nil
+// checks if a source can look up a field, either by its tag or by deriving a key from the field names
+func (l *loader) hasKey(source Source, structField reflect.StructField) bool {
+	_, derives := source.(KeyDeriver)
+	return derives || l.hasTag(source, structField)
+}
+
+// checks if a field is tagged for a source, env keys derived with a naming strategy count as tags while keys derived
+// by the source do not
+func (l *loader) hasTag(source Source, structField reflect.StructField) bool {
+	_, tagged := structField.Tag.Lookup(source.Tag())
+	return tagged || source.Tag() == envTag && l.naming != nil
 }
 
 // set will set the loaded value to the param, or return an error
 func setValue(structField reflect.StructField, param reflect.Value, value string) error {
+	// secrets are loaded through the value they wrap
+	if param.CanAddr() {
+		if secret, ok := param.Addr().Interface().(secretWrapper); ok {
+			return setValue(structField, secret.secretValue(), value)
+		}
+	}
+	// times are parsed with their layout tag unless a parser is registered for them
+	if _, ok := getParser(param.Type()); !ok {
+		set, err := setTimeValue(structField, param, value)
+		if set {
+			return err
+		}
+	}
+	// types with their own decoding take priority over their kind
+	decoded, err := decodeValue(param, value)
+	if err != nil {
+		return newError(ErrInvalidFormat, structField.Name, "value could not be decoded")
+	}
+	if decoded {
+		return nil
+	}
 	switch param.Type().Kind() {
 	case reflect.Bool:
 		v, err := strconv.ParseBool(value)
@@ -167,15 +613,21 @@ func setValue(structField reflect.StructField, param reflect.Value, value string
 		}
 		param.SetFloat(v)
 	case reflect.Map:
+		values, ok := splitItems(trimBrackets(value), getSeparator(structField.Tag))
+		if !ok {
+			return newError(ErrInvalidFormat, structField.Name, "value has unbalanced brackets")
+		}
+		// an empty nested map, IE: []
+		if value == "[]" {
+			values = nil
+		}
 		var (
-			separator   = getSeparator(structField.Tag)
-			values      = strings.Split(value, separator)
 			kvSeparator = getKvSeparator(structField.Tag)
 			t           = reflect.MakeMapWithSize(param.Type(), len(values))
 		)
 		for i := range values {
 			var (
-				kv    = strings.Split(values[i], kvSeparator)
+				kv, _ = splitItems(values[i], kvSeparator)
 				key   = reflect.New(param.Type().Key()).Elem()
 				value = reflect.New(param.Type().Elem()).Elem()
 			)
@@ -194,7 +646,15 @@ func setValue(structField reflect.StructField, param reflect.Value, value string
 		}
 		param.Set(t)
 	case reflect.Slice:
-		values := strings.Split(value, getSeparator(structField.Tag))
+		values, ok := splitItems(trimBrackets(value), getSeparator(structField.Tag))
+		if !ok {
+			return newError(ErrInvalidFormat, structField.Name, "value has unbalanced brackets")
+		}
+		// an empty nested slice, IE: []
+		if value == "[]" {
+			param.Set(reflect.MakeSlice(param.Type(), 0, 0))
+			break
+		}
 		param.Grow(len(values))
 		param.SetCap(len(values))
 		param.SetLen(len(values))
@@ -204,6 +664,14 @@ func setValue(structField reflect.StructField, param reflect.Value, value string
 				return err
 			}
 		}
+	case reflect.Ptr:
+		// pointers are only allocated once there is a value to set
+		v := reflect.New(param.Type().Elem())
+		err := setValue(structField, v.Elem(), value)
+		if err != nil {
+			return err
+		}
+		param.Set(v)
 	case reflect.String:
 		param.SetString(value)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
@@ -218,6 +686,83 @@ func setValue(structField reflect.StructField, param reflect.Value, value string
 	return nil
 }
 
+// nodeSource is implemented by sources that keep the structure of their values, such as config files. lookupNode
+// returns the parsed value at key, where arrays are []any and objects are map[string]any.
+type nodeSource interface {
+	Source
+	lookupNode(key string) any
+}
+
+// sets a param from a parsed value, setting the items of slices and maps one by one so items that contain separators
+// are not split. Other values are set from their string form.
+func setNode(structField reflect.StructField, param reflect.Value, node any) error {
+	// secrets are loaded through the value they wrap
+	if param.CanAddr() {
+		if secret, ok := param.Addr().Interface().(secretWrapper); ok {
+			return setNode(structField, secret.secretValue(), node)
+		}
+	}
+	t := param.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch n := node.(type) {
+	case []any:
+		if t.Kind() != reflect.Slice || isDecodable(t) {
+			break
+		}
+		if param.Kind() == reflect.Ptr {
+			return setNodePtr(structField, param, node)
+		}
+		slice := reflect.MakeSlice(param.Type(), len(n), len(n))
+		for i, item := range n {
+			err := setNode(structField, slice.Index(i), item)
+			if err != nil {
+				return err
+			}
+		}
+		param.Set(slice)
+		return nil
+	case map[string]any:
+		if t.Kind() != reflect.Map || isDecodable(t) {
+			break
+		}
+		if param.Kind() == reflect.Ptr {
+			return setNodePtr(structField, param, node)
+		}
+		m := reflect.MakeMapWithSize(param.Type(), len(n))
+		for k, item := range n {
+			var (
+				key   = reflect.New(param.Type().Key()).Elem()
+				value = reflect.New(param.Type().Elem()).Elem()
+			)
+			err := setValue(structField, key, k)
+			if err != nil {
+				return err
+			}
+			err = setNode(structField, value, item)
+			if err != nil {
+				return err
+			}
+			m.SetMapIndex(key, value)
+		}
+		param.Set(m)
+		return nil
+	}
+	return setValue(structField, param, configValue(node, false))
+}
+
+// sets a pointer param from a parsed value, allocating it once the value is set
+func setNodePtr(structField reflect.StructField, param reflect.Value, node any) error {
+	v := reflect.New(param.Type().Elem())
+	err := setNode(structField, v.Elem(), node)
+	if err != nil {
+		return err
+	}
+	param.Set(v)
+	return nil
+}
+
 func getSeparator(structTag reflect.StructTag) string {
 	separator := defaultSeparator
 	// get the separator from the tags
@@ -235,3 +780,58 @@ func getKvSeparator(structTag reflect.StructTag) string {
 	}
 	return separator
 }
+
+// splits a value by the separator, ignoring separators inside brackets so nested collections can be written as
+// a:[1,2],b:[3]. Returns false when the brackets in the value are unbalanced.
+func splitItems(value, separator string) ([]string, bool) {
+	if separator == "" {
+		return strings.Split(value, separator), true
+	}
+	var (
+		items []string
+		depth int
+		start int
+	)
+	for i := 0; i < len(value); {
+		switch {
+		case value[i] == openBracket:
+			depth++
+		case value[i] == closeBracket:
+			depth--
+			if depth < 0 {
+				return nil, false
+			}
+		case depth == 0 && strings.HasPrefix(value[i:], separator):
+			items = append(items, value[start:i])
+			i += len(separator)
+			start = i
+			continue
+		}
+		i++
+	}
+	if depth != 0 {
+		return nil, false
+	}
+	return append(items, value[start:]), true
+}
+
+// removes the brackets wrapping a whole nested collection, IE: [1,2] but not [1],[2]
+func trimBrackets(value string) string {
+	if len(value) < 2 || value[0] != openBracket || value[len(value)-1] != closeBracket {
+		return value
+	}
+	depth := 0
+	for i := 0; i < len(value)-1; i++ {
+		switch value[i] {
+		case openBracket:
+			depth++
+		case closeBracket:
+			depth--
+		}
+		// the first bracket closed before the end of the value
+		if depth == 0 {
+			return value
+		}
+	}
+	return value[1 : len(value)-1]
+}
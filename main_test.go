@@ -5,6 +5,8 @@ import (
 	"log/slog"
 	"os"
 	"reflect"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
@@ -65,6 +67,16 @@ type unsupportedTypeConfig struct {
 	UnsupportedType func() `env:"MY_UNSUPPORTED_TYPE" default:"not supported"`
 }
 
+type exampleManyErrorsConfig struct {
+	Int      int     `env:"MY_INT" default:"not an int"`
+	Required string  `env:"MY_STRING" required:"true"`
+	Float    float64 `env:"MY_FLOAT64" default:"not a float"`
+	Nested   struct {
+		Bool bool `env:"MY_BOOL" default:"not a bool"`
+	}
+	Func func() `default:"not supported"`
+}
+
 type mapWithCsvString struct {
 	Map map[string]string `env:"MY_MAP" separator:"|"`
 }
@@ -212,9 +224,10 @@ func TestLoad(t *testing.T) {
 			},
 			input: &exampleDefaultConfig{},
 			expectedError: environ.EnvError{
-				Err:   environ.ErrInvalidFormat,
-				Key:   "Int",
-				Extra: "value is not a valid integer representation",
+				Err:    environ.ErrInvalidFormat,
+				Key:    "Int",
+				Source: "env",
+				Extra:  "value is not a valid integer representation",
 			},
 			clean: func() {
 				os.Unsetenv("MY_INT")
@@ -226,9 +239,10 @@ func TestLoad(t *testing.T) {
 			},
 			input: &exampleDefaultConfig{},
 			expectedError: environ.EnvError{
-				Err:   environ.ErrInvalidFormat,
-				Key:   "Uint",
-				Extra: "value is not a valid uint representation",
+				Err:    environ.ErrInvalidFormat,
+				Key:    "Uint",
+				Source: "env",
+				Extra:  "value is not a valid uint representation",
 			},
 			clean: func() {
 				os.Unsetenv("MY_UINT")
@@ -240,9 +254,10 @@ func TestLoad(t *testing.T) {
 			},
 			input: &exampleDefaultConfig{},
 			expectedError: environ.EnvError{
-				Err:   environ.ErrInvalidFormat,
-				Key:   "Float32",
-				Extra: "value is not a valid float representation",
+				Err:    environ.ErrInvalidFormat,
+				Key:    "Float32",
+				Source: "env",
+				Extra:  "value is not a valid float representation",
 			},
 			clean: func() {
 				os.Unsetenv("MY_FLOAT32")
@@ -254,9 +269,10 @@ func TestLoad(t *testing.T) {
 			},
 			input: &exampleDefaultConfig{},
 			expectedError: environ.EnvError{
-				Err:   environ.ErrInvalidFormat,
-				Key:   "Bool",
-				Extra: "value is not a valid boolean representation",
+				Err:    environ.ErrInvalidFormat,
+				Key:    "Bool",
+				Source: "env",
+				Extra:  "value is not a valid boolean representation",
 			},
 			clean: func() {
 				os.Unsetenv("MY_BOOL")
@@ -268,9 +284,10 @@ func TestLoad(t *testing.T) {
 			},
 			input: &exampleDefaultConfig{},
 			expectedError: environ.EnvError{
-				Err:   environ.ErrInvalidFormat,
-				Key:   "Map",
-				Extra: "a map item has more than one kv_separator",
+				Err:    environ.ErrInvalidFormat,
+				Key:    "Map",
+				Source: "env",
+				Extra:  "a map item has more than one kv_separator",
 			},
 			clean: func() {
 				os.Unsetenv("MY_MAP")
@@ -282,9 +299,10 @@ func TestLoad(t *testing.T) {
 			},
 			input: &exampleDefaultConfig{},
 			expectedError: environ.EnvError{
-				Err:   environ.ErrInvalidFormat,
-				Key:   "MapWithCustomSeps",
-				Extra: "value is not a valid integer representation",
+				Err:    environ.ErrInvalidFormat,
+				Key:    "MapWithCustomSeps",
+				Source: "env",
+				Extra:  "value is not a valid integer representation",
 			},
 			clean: func() {
 				os.Unsetenv("MY_CUSTOM_MAP")
@@ -296,9 +314,10 @@ func TestLoad(t *testing.T) {
 			},
 			input: &exampleDefaultConfig{},
 			expectedError: environ.EnvError{
-				Err:   environ.ErrInvalidFormat,
-				Key:   "MapWithCustomSeps",
-				Extra: "value is not a valid integer representation",
+				Err:    environ.ErrInvalidFormat,
+				Key:    "MapWithCustomSeps",
+				Source: "env",
+				Extra:  "value is not a valid integer representation",
 			},
 			clean: func() {
 				os.Unsetenv("MY_CUSTOM_MAP")
@@ -310,9 +329,10 @@ func TestLoad(t *testing.T) {
 			},
 			input: &exampleDefaultConfig{},
 			expectedError: environ.EnvError{
-				Err:   environ.ErrInvalidFormat,
-				Key:   "SliceWithCustomSep",
-				Extra: "value is not a valid integer representation",
+				Err:    environ.ErrInvalidFormat,
+				Key:    "SliceWithCustomSep",
+				Source: "env",
+				Extra:  "value is not a valid integer representation",
 			},
 			clean: func() {
 				os.Unsetenv("MY_CUSTOM_SLICE")
@@ -334,9 +354,10 @@ func TestLoad(t *testing.T) {
 			prep:  unsetTestEnv,
 			input: &exampleRequiredConfig{},
 			expectedError: environ.EnvError{
-				Err:   environ.ErrRequiredNotFound,
-				Key:   "RequiredField",
-				Extra: "required field not loaded",
+				Err:    environ.ErrRequiredNotFound,
+				Key:    "RequiredField",
+				Source: "env",
+				Extra:  "required field not loaded",
 			},
 		},
 		"with invalid required config struct": {
@@ -360,9 +381,10 @@ func TestLoad(t *testing.T) {
 		"with an unsupported type in the config": {
 			input: &unsupportedTypeConfig{},
 			expectedError: environ.EnvError{
-				Err:   environ.ErrUnsupportedType,
-				Key:   "UnsupportedType",
-				Extra: "provided type is not supported in this version",
+				Err:    environ.ErrUnsupportedType,
+				Key:    "UnsupportedType",
+				Source: "default",
+				Extra:  "provided type is not supported in this version",
 			},
 		},
 		"with a csv string as a map item": {
@@ -377,10 +399,6 @@ func TestLoad(t *testing.T) {
 				os.Unsetenv("MY_MAP")
 			},
 		},
-		// TODO: add AWS Parameter Store
-		// TODO: adsd AWS Secrets Manager
-		// TODO: add GCP Secrets
-		// TODO: add Swift Object Store
 	}
 
 	for name, tc := range testCases {
@@ -424,3 +442,553 @@ func TestLoad(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadCollectsErrors(t *testing.T) {
+	unsetTestEnv()
+	err := environ.Load(&exampleManyErrorsConfig{})
+
+	var multiErr *environ.MultiError
+	if !errors.As(err, &multiErr) {
+		slog.Error("expected a multi error", "error", err)
+		t.FailNow()
+	}
+	expectedErrors := []environ.EnvError{
+		{Err: environ.ErrInvalidFormat, Key: "Int", Source: "default", Extra: "value is not a valid integer representation"},
+		{Err: environ.ErrRequiredNotFound, Key: "Required", Source: "env", Extra: "required field not loaded"},
+		{Err: environ.ErrInvalidFormat, Key: "Float", Source: "default", Extra: "value is not a valid float representation"},
+		{Err: environ.ErrInvalidFormat, Key: "Bool", Source: "default", Extra: "value is not a valid boolean representation"},
+		{Err: environ.ErrUnsupportedType, Key: "Func", Source: "default", Extra: "provided type is not supported in this version"},
+	}
+	if len(multiErr.Errors) != len(expectedErrors) {
+		slog.Error("expected errors don't match errors", "expected errors", expectedErrors, "errors", multiErr.Errors)
+		t.FailNow()
+	}
+	for i := range expectedErrors {
+		if expectedErrors[i] != *multiErr.Errors[i] {
+			slog.Error("expected error didn't match error", "expected error", expectedErrors[i], "error", *multiErr.Errors[i])
+			t.Fail()
+		}
+	}
+	for _, sentinel := range []error{environ.ErrInvalidFormat, environ.ErrRequiredNotFound, environ.ErrUnsupportedType} {
+		if !errors.Is(err, sentinel) {
+			slog.Error("errors.Is does not match a collected error", "sentinel", sentinel)
+			t.Fail()
+		}
+	}
+}
+
+type exampleMysqlConfig struct {
+	Host string `env:"MYSQL_HOST" required:"true"`
+	Port int    `env:"MYSQL_PORT" default:"3306"`
+}
+
+type examplePrefixedConfig struct {
+	Name    string             `env:"NAME"`
+	Primary exampleMysqlConfig `envPrefix:"PRIMARY_"`
+	Replica exampleMysqlConfig `envPrefix:"REPLICA_"`
+	Nested  struct {
+		Cache struct {
+			Host string `env:"HOST"`
+		} `envPrefix:"CACHE_"`
+	} `envPrefix:"NESTED_"`
+}
+
+func TestLoadPrefixes(t *testing.T) {
+	testCases := map[string]struct {
+		env            map[string]string
+		opts           []environ.Option
+		expectedResult examplePrefixedConfig
+		expectedError  environ.EnvError
+	}{
+		"nested prefixes": {
+			env: map[string]string{
+				"NAME":                   "service",
+				"PRIMARY_MYSQL_HOST":     "primary.internal",
+				"REPLICA_MYSQL_HOST":     "replica.internal",
+				"REPLICA_MYSQL_PORT":     "3307",
+				"NESTED_CACHE_HOST":      "cache.internal",
+				"MYSQL_HOST":             "unprefixed.internal",
+				"APP_NAME":               "app service",
+				"APP_PRIMARY_MYSQL_HOST": "app.primary.internal",
+			},
+			expectedResult: examplePrefixedConfig{
+				Name:    "service",
+				Primary: exampleMysqlConfig{Host: "primary.internal", Port: 3306},
+				Replica: exampleMysqlConfig{Host: "replica.internal", Port: 3307},
+			},
+		},
+		"global prefix": {
+			env: map[string]string{
+				"APP_NAME":               "service",
+				"APP_PRIMARY_MYSQL_HOST": "primary.internal",
+				"APP_REPLICA_MYSQL_HOST": "replica.internal",
+				"APP_NESTED_CACHE_HOST":  "cache.internal",
+				"PRIMARY_MYSQL_HOST":     "unprefixed.internal",
+			},
+			opts: []environ.Option{environ.WithPrefix("APP_")},
+			expectedResult: examplePrefixedConfig{
+				Name:    "service",
+				Primary: exampleMysqlConfig{Host: "primary.internal", Port: 3306},
+				Replica: exampleMysqlConfig{Host: "replica.internal", Port: 3306},
+			},
+		},
+		"required prefixed value not set": {
+			env: map[string]string{
+				"PRIMARY_MYSQL_HOST": "primary.internal",
+				"MYSQL_HOST":         "unprefixed.internal",
+			},
+			expectedError: environ.EnvError{
+				Err:    environ.ErrRequiredNotFound,
+				Key:    "Host",
+				Source: "env",
+				Extra:  "required field not loaded",
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			var cfg examplePrefixedConfig
+			err := environ.Load(&cfg, tc.opts...)
+			var envErr *environ.EnvError
+			if errors.As(err, &envErr) {
+				if tc.expectedError != *envErr {
+					slog.Error("expected error didn't match error", "expected error", tc.expectedError, "error", *envErr)
+					t.Fail()
+				}
+				return
+			}
+			if tc.expectedError.Err != nil {
+				slog.Error("no error occured where an error was expected", "expected error", tc.expectedError)
+				t.FailNow()
+			}
+			tc.expectedResult.Nested.Cache.Host = "cache.internal"
+			if !reflect.DeepEqual(cfg, tc.expectedResult) {
+				slog.Error("expected result does not match result", "expected result", tc.expectedResult, "result", cfg)
+				t.Fail()
+			}
+		})
+	}
+}
+
+type examplePointerConfig struct {
+	Int         *int                `env:"MY_INT"`
+	String      *string             `env:"MY_STRING"`
+	Defaulted   *bool               `env:"MY_BOOL" default:"true"`
+	Slice       []*int              `env:"MY_SLICE"`
+	Map         map[string]*float64 `env:"MY_MAP"`
+	Optional    *exampleMysqlConfig `envPrefix:"OPTIONAL_"`
+	Preset      *exampleMysqlConfig `envPrefix:"PRESET_"`
+	Recursive   *exampleRecursiveConfig
+	DoublePoint **int `env:"MY_INT"`
+}
+
+type exampleRecursiveConfig struct {
+	Name string `env:"MY_RECURSIVE_NAME"`
+	Next *exampleRecursiveConfig
+}
+
+func TestLoadPointers(t *testing.T) {
+	var (
+		one   = 1
+		two   = 2
+		yes   = true
+		half  = 0.5
+		value = "value"
+		ptr   = &one
+	)
+	testCases := map[string]struct {
+		env            map[string]string
+		input          *examplePointerConfig
+		expectedResult *examplePointerConfig
+		expectedError  environ.EnvError
+	}{
+		"unset pointers stay nil": {
+			env: map[string]string{
+				"PRESET_MYSQL_HOST": "preset.internal",
+			},
+			input: &examplePointerConfig{Preset: &exampleMysqlConfig{}},
+			expectedResult: &examplePointerConfig{
+				Defaulted: &yes,
+				Preset:    &exampleMysqlConfig{Host: "preset.internal", Port: 3306},
+			},
+		},
+		"loaded pointers are allocated": {
+			env: map[string]string{
+				"MY_INT":              "1",
+				"MY_STRING":           "value",
+				"MY_SLICE":            "1,2",
+				"MY_MAP":              "a:0.5",
+				"OPTIONAL_MYSQL_PORT": "3307",
+				"OPTIONAL_MYSQL_HOST": "optional.internal",
+				"PRESET_MYSQL_HOST":   "preset.internal",
+				"MY_RECURSIVE_NAME":   "recursive",
+			},
+			input: &examplePointerConfig{},
+			expectedResult: &examplePointerConfig{
+				Int:         &one,
+				String:      &value,
+				Defaulted:   &yes,
+				Slice:       []*int{&one, &two},
+				Map:         map[string]*float64{"a": &half},
+				Optional:    &exampleMysqlConfig{Host: "optional.internal", Port: 3307},
+				Preset:      &exampleMysqlConfig{Host: "preset.internal", Port: 3306},
+				Recursive:   &exampleRecursiveConfig{Name: "recursive"},
+				DoublePoint: &ptr,
+			},
+		},
+		"required fields of a loaded struct pointer": {
+			env: map[string]string{
+				"OPTIONAL_MYSQL_PORT": "3307",
+				"PRESET_MYSQL_HOST":   "preset.internal",
+			},
+			input: &examplePointerConfig{},
+			expectedError: environ.EnvError{
+				Err:    environ.ErrRequiredNotFound,
+				Key:    "Host",
+				Source: "env",
+				Extra:  "required field not loaded",
+			},
+		},
+		"bad pointer value": {
+			env: map[string]string{
+				"MY_INT":            "not an int",
+				"PRESET_MYSQL_HOST": "preset.internal",
+			},
+			input: &examplePointerConfig{},
+			expectedError: environ.EnvError{
+				Err:    environ.ErrInvalidFormat,
+				Key:    "Int",
+				Source: "env",
+				Extra:  "value is not a valid integer representation",
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			unsetTestEnv()
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			err := environ.Load(tc.input)
+			var envErr *environ.EnvError
+			if errors.As(err, &envErr) {
+				if tc.expectedError != *envErr {
+					slog.Error("expected error didn't match error", "expected error", tc.expectedError, "error", *envErr)
+					t.Fail()
+				}
+				return
+			}
+			if tc.expectedError.Err != nil {
+				slog.Error("no error occured where an error was expected", "expected error", tc.expectedError)
+				t.FailNow()
+			}
+			if !reflect.DeepEqual(tc.input, tc.expectedResult) {
+				slog.Error("expected result does not match result", "expected result", tc.expectedResult, "result", tc.input)
+				t.Fail()
+			}
+		})
+	}
+}
+
+type exampleNestedCollectionsConfig struct {
+	Routes         map[string][]string         `env:"MY_ROUTES"`
+	Allowlists     map[string][]int            `env:"MY_ALLOWLISTS" separator:";" kv_separator:"="`
+	Matrix         [][]int                     `env:"MY_MATRIX"`
+	Tenants        []map[string]string         `env:"MY_TENANTS"`
+	Deep           map[string]map[string][]int `env:"MY_DEEP"`
+	BracketedSlice []string                    `env:"MY_BRACKETED_SLICE"`
+}
+
+func TestLoadNestedCollections(t *testing.T) {
+	testCases := map[string]struct {
+		env            map[string]string
+		expectedResult exampleNestedCollectionsConfig
+		expectedError  environ.EnvError
+	}{
+		"nested collections": {
+			env: map[string]string{
+				"MY_ROUTES":          "/api:[svc-a,svc-b],/web:[svc-c]",
+				"MY_ALLOWLISTS":      "acme=[1;2;3];globex=[4]",
+				"MY_MATRIX":          "[1,2],[3,4],[]",
+				"MY_TENANTS":         "[name:acme,tier:gold],[name:globex]",
+				"MY_DEEP":            "a:[b:[1,2],c:[3]]",
+				"MY_BRACKETED_SLICE": "[x,y]",
+			},
+			expectedResult: exampleNestedCollectionsConfig{
+				Routes:         map[string][]string{"/api": {"svc-a", "svc-b"}, "/web": {"svc-c"}},
+				Allowlists:     map[string][]int{"acme": {1, 2, 3}, "globex": {4}},
+				Matrix:         [][]int{{1, 2}, {3, 4}, {}},
+				Tenants:        []map[string]string{{"name": "acme", "tier": "gold"}, {"name": "globex"}},
+				Deep:           map[string]map[string][]int{"a": {"b": {1, 2}, "c": {3}}},
+				BracketedSlice: []string{"x", "y"},
+			},
+		},
+		"unbalanced brackets": {
+			env: map[string]string{
+				"MY_ROUTES": "/api:[svc-a,svc-b",
+			},
+			expectedError: environ.EnvError{
+				Err:    environ.ErrInvalidFormat,
+				Key:    "Routes",
+				Source: "env",
+				Extra:  "value has unbalanced brackets",
+			},
+		},
+		"nested value without brackets": {
+			env: map[string]string{
+				"MY_ROUTES": "/api:svc-a,svc-b",
+			},
+			expectedError: environ.EnvError{
+				Err:    environ.ErrInvalidFormat,
+				Key:    "Routes",
+				Source: "env",
+				Extra:  "a map item has more than one kv_separator",
+			},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			for k, v := range tc.env {
+				t.Setenv(k, v)
+			}
+			var cfg exampleNestedCollectionsConfig
+			err := environ.Load(&cfg)
+			var envErr *environ.EnvError
+			if errors.As(err, &envErr) {
+				if tc.expectedError != *envErr {
+					slog.Error("expected error didn't match error", "expected error", tc.expectedError, "error", *envErr)
+					t.Fail()
+				}
+				return
+			}
+			if tc.expectedError.Err != nil {
+				slog.Error("no error occured where an error was expected", "expected error", tc.expectedError)
+				t.FailNow()
+			}
+			if !reflect.DeepEqual(cfg, tc.expectedResult) {
+				slog.Error("expected result does not match result", "expected result", tc.expectedResult, "result", cfg)
+				t.Fail()
+			}
+		})
+	}
+}
+
+type examplePrivateStateConfig struct {
+	Host    string `env:"PRIVATE_HOST" default:"localhost"`
+	Ignored string `env:"-"`
+	Nested  struct {
+		Port  int `env:"PRIVATE_PORT" default:"8080"`
+		cache map[string]string
+	}
+	mu sync.Mutex
+}
+
+type exampleStrictConfig struct {
+	Host     string `env:"STRICT_HOST"`
+	Port     int
+	Name     string `default:"service"`
+	Ignored  string `env:"-"`
+	Database struct {
+		User string
+	}
+	Backends []struct {
+		Host string `env:"HOST"`
+	}
+}
+
+func TestLoadSkipUnexported(t *testing.T) {
+	t.Setenv("-", "should not load")
+	var cfg examplePrivateStateConfig
+	err := environ.Load(&cfg, environ.WithSkipUnexported())
+	if err != nil || cfg.Host != "localhost" || cfg.Nested.Port != 8080 || cfg.Ignored != "" {
+		slog.Error("failed to skip unexported fields", "error", err)
+		t.Fail()
+	}
+
+	// unexported fields are still reported without the option
+	var envErr *environ.EnvError
+	err = environ.Load(&cfg)
+	if !errors.As(err, &envErr) || envErr.Err != environ.ErrUnsettableParam {
+		slog.Error("expected unexported fields to be reported", "error", err)
+		t.Fail()
+	}
+}
+
+func TestLoadStrict(t *testing.T) {
+	var cfg exampleStrictConfig
+	err := environ.Load(&cfg, environ.WithStrict())
+	expected := []environ.EnvError{
+		{Err: environ.ErrMissingTag, Key: "Port", Extra: "field has no tag for any source"},
+		{Err: environ.ErrMissingTag, Key: "User", Extra: "field has no tag for any source"},
+		{Err: environ.ErrMissingTag, Key: "Backends", Extra: "field has no tag for any source"},
+	}
+	var multiErr *environ.MultiError
+	if !errors.As(err, &multiErr) || len(multiErr.Errors) != len(expected) {
+		slog.Error("expected every untagged field to be reported", "error", err)
+		t.FailNow()
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(*multiErr.Errors[i], expected[i]) {
+			slog.Error("expected error didn't match error", "expected error", expected[i], "error", multiErr.Errors[i])
+			t.Fail()
+		}
+	}
+
+	// keys derived by sources such as config files do not count as tags
+	source, err := environ.NewJSONFileSource(writeConfigFile(t, "config.json", `{"port": 8080}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = environ.Load(&cfg, environ.WithStrict(), environ.WithSources(environ.EnvSource(), source))
+	if !errors.As(err, &multiErr) || len(multiErr.Errors) != len(expected) {
+		slog.Error("expected untagged fields to be reported with a config file source", "error", err)
+		t.Fail()
+	}
+
+	// untagged fields are ignored without the option
+	cfg = exampleStrictConfig{}
+	err = environ.Load(&cfg)
+	if err != nil {
+		slog.Error("expected untagged fields to be ignored", "error", err)
+		t.Fail()
+	}
+}
+
+type exampleBaseConfig struct {
+	Host string `env:"HOST" default:"localhost"`
+	Port int    `env:"PORT" default:"8080"`
+}
+
+type ExampleTLSConfig struct {
+	Cert string `env:"TLS_CERT"`
+}
+
+type exampleEmbeddingConfig struct {
+	exampleBaseConfig
+	*ExampleTLSConfig
+	Admin struct {
+		exampleBaseConfig `envPrefix:"ADMIN_"`
+	}
+	Name string `env:"NAME" config:"name"`
+}
+
+func TestLoadEmbedded(t *testing.T) {
+	t.Setenv("HOST", "api.internal")
+	t.Setenv("ADMIN_PORT", "9090")
+	t.Setenv("TLS_CERT", "cert.pem")
+
+	var cfg exampleEmbeddingConfig
+	err := environ.Load(&cfg)
+	if err != nil {
+		slog.Error("failed to load embedded config", "error", err)
+		t.FailNow()
+	}
+	if cfg.Host != "api.internal" || cfg.Port != 8080 || cfg.Cert != "cert.pem" || cfg.Admin.Host != "localhost" || cfg.Admin.Port != 9090 {
+		slog.Error("embedded structs were not loaded", "result", cfg)
+		t.Fail()
+	}
+
+	// embedded pointers are allocated even when none of their fields are set
+	os.Unsetenv("TLS_CERT")
+	cfg = exampleEmbeddingConfig{}
+	err = environ.Load(&cfg)
+	if err != nil || cfg.ExampleTLSConfig == nil {
+		slog.Error("embedded pointer was not allocated", "error", err)
+		t.Fail()
+	}
+
+	// fields of embedded structs are promoted, so their derived keys are not nested under the embedded type
+	source, err := environ.NewJSONFileSource(writeConfigFile(t, "config.json", `{"host": "file.internal", "admin": {"port": 7070}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Unsetenv("HOST")
+	os.Unsetenv("ADMIN_PORT")
+	cfg = exampleEmbeddingConfig{}
+	err = environ.Load(&cfg, environ.WithSources(source))
+	if err != nil || cfg.Host != "file.internal" || cfg.Admin.Port != 7070 {
+		slog.Error("embedded fields were not loaded from derived keys", "result", cfg, "error", err)
+		t.Fail()
+	}
+}
+
+type exampleFallbackConfig struct {
+	Host     string `env:"MYSQL_HOST,DB_HOST" required:"true"`
+	Port     int    `env:"MYSQL_PORT, DB_PORT, LEGACY_PORT" default:"3306"`
+	Database struct {
+		Name string `env:"MYSQL_DATABASE,DB_NAME"`
+	}
+	Replicas []struct {
+		Host string `env:"HOST,ADDR"`
+	} `env:"MYSQL_REPLICAS,DB_REPLICAS"`
+}
+
+func TestLoadFallbackKeys(t *testing.T) {
+	t.Setenv("MYSQL_HOST", "mysql.internal")
+	t.Setenv("DB_HOST", "legacy.internal")
+	t.Setenv("LEGACY_PORT", "3307")
+	t.Setenv("DB_NAME", "app")
+	t.Setenv("MYSQL_REPLICAS_0_ADDR", "replica.internal")
+
+	type deprecation struct {
+		field, legacyKey, key string
+	}
+	var deprecations []deprecation
+	hook := func(field, legacyKey, key string) {
+		deprecations = append(deprecations, deprecation{field: field, legacyKey: legacyKey, key: key})
+	}
+	var cfg exampleFallbackConfig
+	err := environ.Load(&cfg, environ.WithDeprecationHook(hook))
+	if err != nil {
+		slog.Error("failed to load config with fallback keys", "error", err)
+		t.FailNow()
+	}
+	var expected exampleFallbackConfig
+	expected.Host = "mysql.internal"
+	expected.Port = 3307
+	expected.Database.Name = "app"
+	expected.Replicas = []struct {
+		Host string `env:"HOST,ADDR"`
+	}{{Host: "replica.internal"}}
+	if !reflect.DeepEqual(cfg, expected) {
+		slog.Error("expected result does not match result", "expected result", expected, "result", cfg)
+		t.Fail()
+	}
+	expectedDeprecations := []deprecation{
+		{field: "Port", legacyKey: "LEGACY_PORT", key: "MYSQL_PORT"},
+		{field: "Database.Name", legacyKey: "DB_NAME", key: "MYSQL_DATABASE"},
+		{field: "Replicas.0.Host", legacyKey: "MYSQL_REPLICAS_0_ADDR", key: "MYSQL_REPLICAS_0_HOST"},
+	}
+	if !reflect.DeepEqual(deprecations, expectedDeprecations) {
+		slog.Error("expected deprecations do not match deprecations", "expected deprecations", expectedDeprecations, "deprecations", deprecations)
+		t.Fail()
+	}
+
+	// fallback keys satisfy required fields, and the hook is optional
+	os.Unsetenv("MYSQL_HOST")
+	cfg = exampleFallbackConfig{}
+	err = environ.Load(&cfg)
+	if err != nil || cfg.Host != "legacy.internal" {
+		slog.Error("failed to load required field from a fallback key", "result", cfg, "error", err)
+		t.Fail()
+	}
+
+	// elements of slices and maps of structs are discovered under fallback keys
+	os.Unsetenv("MYSQL_REPLICAS_0_ADDR")
+	t.Setenv("DB_REPLICAS_0_HOST", "legacy-replica.internal")
+	deprecations = nil
+	cfg = exampleFallbackConfig{}
+	err = environ.Load(&cfg, environ.WithDeprecationHook(hook))
+	if err != nil || len(cfg.Replicas) != 1 || cfg.Replicas[0].Host != "legacy-replica.internal" {
+		slog.Error("failed to load elements from a fallback key", "result", cfg, "error", err)
+		t.Fail()
+	}
+	replicaDeprecation := deprecation{field: "Replicas", legacyKey: "DB_REPLICAS", key: "MYSQL_REPLICAS"}
+	if !slices.Contains(deprecations, replicaDeprecation) {
+		slog.Error("expected a deprecation for the fallback key of the slice", "deprecations", deprecations)
+		t.Fail()
+	}
+}
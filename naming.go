@@ -0,0 +1,63 @@
+package environ
+
+import (
+	"strings"
+	"unicode"
+)
+
+// wordSeparator joins the words of a field name in UPPER_SNAKE_CASE
+const wordSeparator = "_"
+
+// NamingStrategy derives the env key of a field without an env tag. It is given the names of the fields leading to
+// the field, including the field itself, IE: []string{"Database", "MaxOpenConns"}. Nested structs with an envPrefix
+// tag are not part of the names, since the prefix replaces them.
+type NamingStrategy func(names []string) string
+
+// WithNaming derives the env keys of fields without an env tag using the strategy, IE:
+// WithNaming(UpperSnakeCase("_")) loads Database.MaxOpenConns from DATABASE_MAX_OPEN_CONNS. Fields with an env tag
+// always use their tag, and prefixes are added to derived keys the same way as tagged keys.
+func WithNaming(strategy NamingStrategy) Option {
+	return func(l *loader) {
+		l.naming = strategy
+	}
+}
+
+// UpperSnakeCase returns a NamingStrategy that converts each field name to UPPER_SNAKE_CASE and joins them with the
+// delimiter, IE: DATABASE_MAX_OPEN_CONNS with "_" or DATABASE__MAX_OPEN_CONNS with "__". Acronyms are kept together,
+// so HTTPServer becomes HTTP_SERVER.
+func UpperSnakeCase(delimiter string) NamingStrategy {
+	return func(names []string) string {
+		keys := make([]string, len(names))
+		for i, name := range names {
+			keys[i] = toUpperSnakeCase(name)
+		}
+		return strings.Join(keys, delimiter)
+	}
+}
+
+// converts a Go field name to UPPER_SNAKE_CASE. A word starts at an upper case letter that follows a lower case
+// letter or digit, or that is followed by a lower case letter at the end of an acronym.
+func toUpperSnakeCase(name string) string {
+	var (
+		runes = []rune(name)
+		sb    strings.Builder
+	)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && nextIsLower {
+				sb.WriteString(wordSeparator)
+			}
+		}
+		if r == '_' {
+			// keep existing separators without doubling them
+			if i > 0 && !strings.HasSuffix(sb.String(), wordSeparator) {
+				sb.WriteString(wordSeparator)
+			}
+			continue
+		}
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+	return sb.String()
+}
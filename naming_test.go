@@ -0,0 +1,112 @@
+package environ_test
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/NeedMoreVolume/environ"
+)
+
+type exampleNamingConfig struct {
+	Host     string
+	HTTPPort int    `default:"8080"`
+	Token    string `env:"API_TOKEN"`
+	Database struct {
+		MaxOpenConns int
+		User         string `required:"true"`
+	}
+	Cache struct {
+		TTL string
+	} `envPrefix:"REDIS_"`
+	Backends []struct {
+		URL string
+	}
+	Tenants map[string]struct {
+		Quota int
+	}
+	Ignored string `env:"-"`
+}
+
+func TestNamingStrategy(t *testing.T) {
+	t.Setenv("HOST", "api.internal")
+	t.Setenv("HTTP_PORT", "9090")
+	t.Setenv("API_TOKEN", "tagged")
+	t.Setenv("TOKEN", "derived")
+	t.Setenv("DATABASE_MAX_OPEN_CONNS", "20")
+	t.Setenv("DATABASE_USER", "app")
+	t.Setenv("REDIS_TTL", "1m")
+	t.Setenv("BACKENDS_0_URL", "http://a")
+	t.Setenv("TENANTS_ACME_QUOTA", "5")
+	t.Setenv("IGNORED", "ignored")
+
+	var cfg exampleNamingConfig
+	err := environ.Load(&cfg, environ.WithNaming(environ.UpperSnakeCase("_")))
+	if err != nil {
+		slog.Error("failed to load config with derived keys", "error", err)
+		t.FailNow()
+	}
+	var expected exampleNamingConfig
+	expected.Host = "api.internal"
+	expected.HTTPPort = 9090
+	expected.Token = "tagged"
+	expected.Database.MaxOpenConns = 20
+	expected.Database.User = "app"
+	expected.Cache.TTL = "1m"
+	expected.Backends = []struct{ URL string }{{URL: "http://a"}}
+	expected.Tenants = map[string]struct{ Quota int }{"ACME": {Quota: 5}}
+	if !reflect.DeepEqual(cfg, expected) {
+		slog.Error("expected result does not match result", "expected result", expected, "result", cfg)
+		t.Fail()
+	}
+}
+
+func TestNamingStrategyOptions(t *testing.T) {
+	t.Setenv("APP_DATABASE__MAX_OPEN_CONNS", "30")
+	t.Setenv("app.database.user", "custom")
+
+	var cfg exampleNamingConfig
+	err := environ.Load(&cfg, environ.WithPrefix("APP_"), environ.WithNaming(environ.UpperSnakeCase("__")))
+	var envErr *environ.EnvError
+	// the required field is missing, and is reported for the env source since its key is derived
+	if !errors.As(err, &envErr) || envErr.Key != "User" || envErr.Source != "env" || cfg.Database.MaxOpenConns != 30 {
+		slog.Error("expected derived keys with a delimiter and prefix", "result", cfg, "error", err)
+		t.Fail()
+	}
+
+	cfg = exampleNamingConfig{}
+	custom := func(names []string) string {
+		return "app." + strings.ToLower(strings.Join(names, "."))
+	}
+	_ = environ.Load(&cfg, environ.WithNaming(custom))
+	if cfg.Database.User != "custom" {
+		slog.Error("custom naming strategy was not used", "result", cfg)
+		t.Fail()
+	}
+}
+
+func TestUpperSnakeCase(t *testing.T) {
+	testCases := map[string]struct {
+		names    []string
+		expected string
+	}{
+		"single word":      {names: []string{"Host"}, expected: "HOST"},
+		"camel case":       {names: []string{"MaxOpenConns"}, expected: "MAX_OPEN_CONNS"},
+		"leading acronym":  {names: []string{"HTTPServer"}, expected: "HTTP_SERVER"},
+		"trailing acronym": {names: []string{"ServerURL"}, expected: "SERVER_URL"},
+		"digits":           {names: []string{"Oauth2Token"}, expected: "OAUTH2_TOKEN"},
+		"underscores":      {names: []string{"Max_Conns"}, expected: "MAX_CONNS"},
+		"nested":           {names: []string{"Database", "TLSCert"}, expected: "DATABASE_TLS_CERT"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			result := environ.UpperSnakeCase("_")(tc.names)
+			if result != tc.expected {
+				slog.Error("expected result does not match result", "expected result", tc.expected, "result", result)
+				t.Fail()
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package environ
+
+// Option configures a single call to Load
+type Option func(*loader)
+
+// WithSources sets the sources values are read from, in order of precedence. The first source to find a value for a
+// field wins, so WithSources(EnvSource(), ssmSource) lets the environment override parameter store values while
+// WithSources(ssmSource, EnvSource()) does the opposite. The default tag is checked after every source unless
+// DefaultSource is given a place in the order, and file tags are checked after every source unless FileSource is given
+// a place in the order. Defaults never satisfy required fields, so required fields keep checking
+// the sources after DefaultSource.
+//
+// The order can be overridden for a single field with a sources tag listing source tags in order of precedence, IE:
+// `sources:"ssm,env"`. Sources that are not part of the load are skipped.
+func WithSources(sources ...Source) Option {
+	return func(l *loader) {
+		l.sources = sources
+	}
+}
+
+// WithPrefix adds a prefix to the env keys of every field in the config, IE: WithPrefix("APP_") loads a field tagged
+// `env:"PORT"` from APP_PORT. Prefixes from envPrefix tags on nested structs are added after it.
+func WithPrefix(prefix string) Option {
+	return func(l *loader) {
+		l.prefix = prefix
+	}
+}
+
+// WithSkipUnexported skips unexported fields instead of reporting them with ErrUnsettableParam, so configs can hold
+// private state such as a mutex or cache
+func WithSkipUnexported() Option {
+	return func(l *loader) {
+		l.skipUnexported = true
+	}
+}
+
+// WithStrict reports every exported field that has no tag for any source of the load with ErrMissingTag, so forgotten
+// tags are noticed. Only tags are checked, so keys derived by a KeyDeriver such as a config file do not count, while env
+// keys derived with WithNaming do. Fields tagged env:"-" are not reported.
+func WithStrict() Option {
+	return func(l *loader) {
+		l.strict = true
+	}
+}
+
+// DeprecationFunc is called when a field is loaded from one of the fallback keys in its env tag instead of the first
+// key. It is given the path of the field, IE: Database.Host, the fallback key that supplied the value and the key that
+// should be used instead.
+type DeprecationFunc func(field, legacyKey, key string)
+
+// WithDeprecationHook calls the hook whenever a field is loaded from a fallback key, IE: from DB_HOST for a field
+// tagged `env:"MYSQL_HOST,DB_HOST"`, so services that still rely on legacy names can be tracked down
+func WithDeprecationHook(hook DeprecationFunc) Option {
+	return func(l *loader) {
+		l.deprecation = hook
+	}
+}
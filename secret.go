@@ -0,0 +1,153 @@
+package environ
+
+import (
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strconv"
+)
+
+// redacted replaces secret values wherever they would be printed
+const redacted = "[REDACTED]"
+
+// Secret holds a value that must never be printed, such as a password. Secret fields are loaded like a field of type
+// T, but the String, GoString, MarshalJSON and LogValue methods all return [REDACTED] so the value cannot leak
+// through fmt, encoding/json or log/slog. Use Value to read it.
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret wraps a value in a Secret
+func NewSecret[T any](value T) Secret[T] {
+	return Secret[T]{value: value}
+}
+
+// Value returns the secret value
+func (s Secret[T]) Value() T {
+	return s.value
+}
+
+// String redacts the value
+func (s Secret[T]) String() string {
+	return redacted
+}
+
+// GoString redacts the value for %#v
+func (s Secret[T]) GoString() string {
+	return redacted
+}
+
+// MarshalJSON redacts the value
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
+}
+
+// LogValue redacts the value
+func (s Secret[T]) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+// secretValue returns the wrapped value so setValue can load it like a field of type T
+func (s *Secret[T]) secretValue() reflect.Value {
+	return reflect.ValueOf(&s.value).Elem()
+}
+
+// secretWrapper is implemented by Secret, which is loaded through the value it wraps
+type secretWrapper interface {
+	secretValue() reflect.Value
+}
+
+var secretWrapperType = reflect.TypeOf((*secretWrapper)(nil)).Elem()
+
+// checks if a field is flagged with a truthy secret tag
+func isSecretField(structField reflect.StructField) bool {
+	secret, _ := strconv.ParseBool(structField.Tag.Get(secretTag))
+	return secret
+}
+
+// Redact returns a copy of the config that is safe to print, with every field flagged with a `secret:"true"` tag
+// replaced. Secret strings are set to [REDACTED] and secrets of other types are set to their zero value. Nested
+// structs, pointers to structs and slices and maps of structs are copied and redacted as well, so the config passed
+// in is never modified. Secret fields are already redacted when printed and are copied as they are.
+func Redact[T any](config T) T {
+	v := reflect.ValueOf(&config).Elem()
+	v.Set(redactValue(v))
+	return config
+}
+
+// returns a redacted copy of a value
+func redactValue(v reflect.Value) reflect.Value {
+	t := v.Type()
+	switch t.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(t).Elem()
+		out.Set(redactValue(v.Elem()))
+		return out
+	case reflect.Ptr:
+		if v.IsNil() || t.Elem().Kind() != reflect.Struct {
+			return v
+		}
+		out := reflect.New(t.Elem())
+		out.Elem().Set(redactValue(v.Elem()))
+		return out
+	case reflect.Slice:
+		if v.IsNil() || !isStructCollection(t) {
+			return v
+		}
+		out := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(redactValue(v.Index(i)))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() || !isStructCollection(t) {
+			return v
+		}
+		out := reflect.MakeMapWithSize(t, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), redactValue(iter.Value()))
+		}
+		return out
+	case reflect.Struct:
+		if isDecodable(t) {
+			return v
+		}
+		// copying the whole struct keeps unexported fields
+		out := reflect.New(t).Elem()
+		out.Set(v)
+		redactFields(out)
+		return out
+	}
+	return v
+}
+
+// redacts the fields of a copied struct in place. The fields of unexported embedded structs are promoted, so they are
+// redacted through the embedded struct of the copy. Unexported embedded pointers cannot be replaced with a copy and
+// are left as they are.
+func redactFields(out reflect.Value) {
+	t := out.Type()
+	for i := 0; i < t.NumField(); i++ {
+		var (
+			structField = t.Field(i)
+			field       = out.Field(i)
+		)
+		if !structField.IsExported() {
+			if structField.Anonymous && field.Kind() == reflect.Struct && !isDecodable(field.Type()) {
+				redactFields(field)
+			}
+			continue
+		}
+		switch {
+		case !isSecretField(structField):
+			field.Set(redactValue(field))
+		case field.Kind() == reflect.String:
+			field.SetString(redacted)
+		default:
+			field.Set(reflect.Zero(field.Type()))
+		}
+	}
+}
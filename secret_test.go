@@ -0,0 +1,94 @@
+package environ_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/NeedMoreVolume/environ"
+)
+
+type exampleSecretBase struct {
+	APIKey string `env:"SECRET_TEST_API_KEY" secret:"true"`
+}
+
+type exampleSecretConfig struct {
+	exampleSecretBase
+	Password environ.Secret[string]   `env:"SECRET_TEST_PASSWORD" required:"true"`
+	Keys     environ.Secret[[]string] `env:"SECRET_TEST_KEYS" separator:";"`
+	Pin      environ.Secret[int]      `env:"SECRET_TEST_PIN" default:"1234"`
+	Token    string                   `env:"SECRET_TEST_TOKEN" secret:"true"`
+	Port     int                      `env:"SECRET_TEST_PORT" secret:"true"`
+	Host     string                   `env:"SECRET_TEST_HOST"`
+	Database *struct {
+		Password string `env:"SECRET_TEST_DB_PASSWORD" secret:"true"`
+	}
+	Replicas []struct {
+		Password string `env:"PASSWORD" secret:"true"`
+	} `env:"SECRET_TEST_REPLICAS"`
+}
+
+func TestSecret(t *testing.T) {
+	t.Setenv("SECRET_TEST_PASSWORD", "hunter2")
+	t.Setenv("SECRET_TEST_KEYS", "key1;key2")
+	t.Setenv("SECRET_TEST_TOKEN", "token123")
+	t.Setenv("SECRET_TEST_PORT", "5432")
+	t.Setenv("SECRET_TEST_HOST", "db.internal")
+	t.Setenv("SECRET_TEST_DB_PASSWORD", "dbpass")
+	t.Setenv("SECRET_TEST_REPLICAS_0_PASSWORD", "replicapass")
+	t.Setenv("SECRET_TEST_API_KEY", "apikey")
+
+	var cfg exampleSecretConfig
+	err := environ.Load(&cfg)
+	if err != nil {
+		slog.Error("failed to load secret config", "error", err)
+		t.FailNow()
+	}
+	if cfg.Password.Value() != "hunter2" || !reflect.DeepEqual(cfg.Keys.Value(), []string{"key1", "key2"}) || cfg.Pin.Value() != 1234 {
+		slog.Error("secrets were not loaded", "password", cfg.Password.Value(), "keys", cfg.Keys.Value(), "pin", cfg.Pin.Value())
+		t.Fail()
+	}
+
+	// secrets are redacted however they are printed
+	var logged bytes.Buffer
+	slog.New(slog.NewTextHandler(&logged, nil)).Info("config", "password", cfg.Password, "pin", cfg.Pin)
+	encoded, err := json.Marshal(cfg.Password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	printed := map[string]string{
+		"%v":   fmt.Sprintf("%v", cfg.Password),
+		"%+v":  fmt.Sprintf("%+v", cfg),
+		"%#v":  fmt.Sprintf("%#v", cfg.Password),
+		"json": string(encoded),
+		"slog": logged.String(),
+	}
+	for name, output := range printed {
+		if strings.Contains(output, "hunter2") || strings.Contains(output, "key1") || strings.Contains(output, "1234") || !strings.Contains(output, "[REDACTED]") {
+			slog.Error("secret was not redacted", "format", name, "output", output)
+			t.Fail()
+		}
+	}
+
+	// redacting returns a copy with secret tagged fields replaced
+	redacted := environ.Redact(cfg)
+	if redacted.Token != "[REDACTED]" || redacted.Port != 0 || redacted.Host != "db.internal" ||
+		redacted.Database.Password != "[REDACTED]" || redacted.Replicas[0].Password != "[REDACTED]" ||
+		redacted.APIKey != "[REDACTED]" || redacted.Password.Value() != "hunter2" {
+		slog.Error("config was not redacted", "result", fmt.Sprintf("%+v", redacted))
+		t.Fail()
+	}
+	if cfg.Token != "token123" || cfg.Database.Password != "dbpass" || cfg.Replicas[0].Password != "replicapass" || cfg.APIKey != "apikey" {
+		slog.Error("redacting modified the config", "result", fmt.Sprintf("%+v", cfg))
+		t.Fail()
+	}
+	redactedPtr := environ.Redact(&cfg)
+	if redactedPtr == &cfg || redactedPtr.Token != "[REDACTED]" || cfg.Token != "token123" {
+		slog.Error("config pointer was not redacted", "result", fmt.Sprintf("%+v", redactedPtr))
+		t.Fail()
+	}
+}
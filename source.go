@@ -0,0 +1,144 @@
+package environ
+
+import (
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Source is a backend that values can be loaded from. Each source is bound to a struct tag,
+// and the value of that tag on a field is the key the source is asked to look up.
+type Source interface {
+	// Tag returns the name of the struct tag holding the keys for this source
+	Tag() string
+	// Lookup returns the value stored at key and whether it was found, or an error if the
+	// source could not be read
+	Lookup(key string) (string, bool, error)
+}
+
+// envSource reads values from the environment of the current process
+type envSource struct{}
+
+// EnvSource returns the built-in Source that reads environment variables named by `env` tags
+func EnvSource() Source {
+	return envSource{}
+}
+
+// Tag returns the env tag
+func (envSource) Tag() string {
+	return envTag
+}
+
+// Lookup reads the environment variable named by key, empty values are treated as not found. When the variable is
+// not set but the same key with a _FILE suffix is, the value is read from the file at that path instead, IE:
+// MYSQL_PASSWORD_FILE=/run/secrets/mysql_password. The variable always wins when both are set, so unrelated variables
+// such as LOG_FILE do not change how LOG is loaded, and files that do not exist are treated as not found.
+func (envSource) Lookup(key string) (string, bool, error) {
+	v := os.Getenv(key)
+	if v != "" {
+		return v, true, nil
+	}
+	path := os.Getenv(key + fileSuffix)
+	if path == "" {
+		return "", false, nil
+	}
+	return fileSource{}.Lookup(path)
+}
+
+// Keys lists the environment variables that are set to a value, variables with a _FILE suffix are also listed
+// without it
+func (envSource) Keys() ([]string, error) {
+	var keys []string
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		if value == "" {
+			continue
+		}
+		keys = append(keys, key)
+		if trimmed, ok := strings.CutSuffix(key, fileSuffix); ok {
+			keys = append(keys, trimmed)
+		}
+	}
+	return keys, nil
+}
+
+// Lister is implemented by sources that can list every key they hold. Listing is used to discover the elements of
+// slices and maps of structs from indexed keys.
+type Lister interface {
+	Keys() ([]string, error)
+}
+
+// KeyDeriver is implemented by sources that can look up fields without a tag for the source. DeriveKey is given the
+// names of the fields leading to a field, including the field itself, and returns the key to look up.
+type KeyDeriver interface {
+	DeriveKey(path []string) string
+}
+
+// Preloader is implemented by sources that can fetch values in bulk. Preload is called once at the start of every
+// load with each key tagged for the source, before any fields are read.
+type Preloader interface {
+	Preload(keys []string) error
+}
+
+// collects the unique keys tagged for a source across a struct type and its nested structs, including pointers to
+// structs, the elements of slices and maps of structs and the types registered for interfaces
+func collectKeys(structType reflect.Type, tag string) []string {
+	var (
+		keys    []string
+		seen    = map[string]bool{}
+		visited = map[reflect.Type]bool{}
+	)
+	var walk func(t reflect.Type)
+	walk = func(t reflect.Type) {
+		// recursive types are only walked once
+		if visited[t] {
+			return
+		}
+		visited[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			structField := t.Field(i)
+			fieldType := structField.Type
+			if fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Map {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct && !isDecodable(fieldType) {
+				walk(fieldType)
+				continue
+			}
+			// any registered type could be selected for an interface
+			if fieldType.Kind() == reflect.Interface && structField.Tag.Get(typeTag) != "" {
+				for _, impl := range getImplementations(fieldType) {
+					walk(impl)
+				}
+			}
+			if key, ok := structField.Tag.Lookup(tag); ok && !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	walk(structType)
+	return keys
+}
+
+// defaultSource reads values from the default tag itself
+type defaultSource struct{}
+
+// DefaultSource returns the built-in Source for `default` tags, which can be passed to WithSources to check defaults
+// before other sources. Values from this source are never considered loaded for required fields.
+func DefaultSource() Source {
+	return defaultSource{}
+}
+
+// Tag returns the default tag
+func (defaultSource) Tag() string {
+	return defaultTag
+}
+
+// Lookup returns the key as the value, since the tag holds the default value
+func (defaultSource) Lookup(key string) (string, bool, error) {
+	return key, true, nil
+}
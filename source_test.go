@@ -0,0 +1,355 @@
+package environ_test
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/NeedMoreVolume/environ"
+)
+
+var errSourceUnavailable = errors.New("source unavailable")
+
+// mapSource is an in memory source for testing
+type mapSource struct {
+	tag    string
+	values map[string]string
+	err    error
+}
+
+func (m mapSource) Tag() string {
+	return m.tag
+}
+
+func (m mapSource) Lookup(key string) (string, bool, error) {
+	if m.err != nil {
+		return "", false, m.err
+	}
+	v, ok := m.values[key]
+	return v, ok, nil
+}
+
+type exampleMultiSourceConfig struct {
+	Host     string `env:"MY_HOST" kv:"host" default:"localhost"`
+	Port     int    `env:"MY_PORT" kv:"port" default:"3306"`
+	Password string `kv:"password" required:"true"`
+}
+
+func TestLoadWith(t *testing.T) {
+	testCases := map[string]struct {
+		prep           func()
+		sources        []environ.Source
+		input          interface{}
+		expectedResult interface{}
+		expectedError  environ.EnvError
+		clean          func()
+	}{
+		"no sources uses defaults": {
+			input: &exampleDefaultConfig{},
+			expectedResult: &exampleDefaultConfig{
+				Int:                 1,
+				Int8:                1,
+				Int16:               1,
+				Int32:               1,
+				Int64:               1,
+				Uint:                1,
+				Uint8:               1,
+				Uint16:              1,
+				Uint32:              1,
+				Uint64:              1,
+				Float32:             1,
+				Float64:             1,
+				Duration:            10,
+				StringifiedDuration: time.Second,
+				String:              "1",
+				Map:                 map[string]string{"1": "2", "3": "4"},
+				MapWithCustomSeps:   map[int]int{1: 2, 3: 4},
+				Slice:               []string{"1", "2", "3", "4"},
+				SliceWithCustomSep:  []int{1, 2, 3, 4},
+				NestedConfig: exampleNestedConfig{
+					A: "nest_1",
+				},
+			},
+		},
+		"custom source fills tagged fields": {
+			sources: []environ.Source{
+				mapSource{tag: "kv", values: map[string]string{"host": "db.internal", "password": "secret"}},
+			},
+			input: &exampleMultiSourceConfig{},
+			expectedResult: &exampleMultiSourceConfig{
+				Host:     "db.internal",
+				Port:     3306,
+				Password: "secret",
+			},
+		},
+		"first source to find a value wins": {
+			prep: func() {
+				os.Setenv("MY_HOST", "env.internal")
+				os.Setenv("MY_PORT", "3307")
+			},
+			sources: []environ.Source{
+				environ.EnvSource(),
+				mapSource{tag: "kv", values: map[string]string{"host": "db.internal", "port": "3308", "password": "secret"}},
+			},
+			input: &exampleMultiSourceConfig{},
+			expectedResult: &exampleMultiSourceConfig{
+				Host:     "env.internal",
+				Port:     3307,
+				Password: "secret",
+			},
+			clean: func() {
+				os.Unsetenv("MY_HOST")
+				os.Unsetenv("MY_PORT")
+			},
+		},
+		"later sources fill values missing from earlier ones": {
+			prep: func() {
+				os.Setenv("MY_PORT", "3307")
+			},
+			sources: []environ.Source{
+				environ.EnvSource(),
+				mapSource{tag: "kv", values: map[string]string{"host": "db.internal", "port": "3308", "password": "secret"}},
+			},
+			input: &exampleMultiSourceConfig{},
+			expectedResult: &exampleMultiSourceConfig{
+				Host:     "db.internal",
+				Port:     3307,
+				Password: "secret",
+			},
+			clean: func() {
+				os.Unsetenv("MY_PORT")
+			},
+		},
+		"required value not found in any source": {
+			sources: []environ.Source{
+				environ.EnvSource(),
+				mapSource{tag: "kv", values: map[string]string{}},
+			},
+			input: &exampleMultiSourceConfig{},
+			expectedError: environ.EnvError{
+				Err:    environ.ErrRequiredNotFound,
+				Key:    "Password",
+				Source: "kv",
+				Extra:  "required field not loaded",
+			},
+		},
+		"source fails to load": {
+			sources: []environ.Source{
+				mapSource{tag: "kv", err: errSourceUnavailable},
+			},
+			input: &exampleMultiSourceConfig{},
+			expectedError: environ.EnvError{
+				Err:    environ.ErrLoading,
+				Key:    "Host",
+				Source: "kv",
+				Extra:  "failed to read value from kv source",
+				Cause:  errSourceUnavailable,
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// prep the test
+			if tc.prep != nil {
+				tc.prep()
+			}
+			// defer the clean up
+			if tc.clean != nil {
+				defer tc.clean()
+			}
+			// run the test
+			err := environ.LoadWith(tc.input, tc.sources...)
+			// validate error
+			var envErr *environ.EnvError
+			if errors.As(err, &envErr) {
+				if tc.expectedError != *envErr {
+					slog.Error("expected error didn't match error", "expected error", tc.expectedError, "error", *envErr)
+					t.FailNow()
+					return
+				}
+			}
+			if err == nil && tc.expectedError.Err != nil {
+				slog.Error("no error occured where an error was expected", "expected error", tc.expectedError)
+				t.FailNow()
+				return
+			}
+
+			// done checking if this should have errored
+			if tc.expectedError.Err != nil {
+				return
+			}
+
+			// validate result
+			if !reflect.DeepEqual(tc.input, tc.expectedResult) {
+				slog.Error("expected result does not match result", "expected result", tc.expectedResult, "result", tc.input)
+				t.Fail()
+				return
+			}
+		})
+	}
+}
+
+type examplePrecedenceConfig struct {
+	Host     string `env:"MY_HOST" kv:"host" default:"localhost"`
+	Port     int    `env:"MY_PORT" kv:"port" default:"3306" sources:"kv,env"`
+	User     string `env:"MY_USER" kv:"user" default:"admin" sources:"default,env"`
+	Password string `env:"MY_PASSWORD" kv:"password" sources:"vault,env"`
+}
+
+type exampleRequiredDefaultConfig struct {
+	Host string `env:"MY_HOST" default:"localhost" required:"true"`
+}
+
+func TestLoadWithSources(t *testing.T) {
+	kv := mapSource{tag: "kv", values: map[string]string{"host": "kv.internal", "port": "3308", "user": "kv", "password": "kv"}}
+	testCases := map[string]struct {
+		prep           func()
+		opts           []environ.Option
+		input          interface{}
+		expectedResult interface{}
+		expectedError  environ.EnvError
+		clean          func()
+	}{
+		"env beats kv beats default": {
+			prep: func() {
+				os.Setenv("MY_HOST", "env.internal")
+				os.Setenv("MY_PORT", "3307")
+				os.Setenv("MY_USER", "env")
+				os.Setenv("MY_PASSWORD", "env")
+			},
+			opts:  []environ.Option{environ.WithSources(environ.EnvSource(), kv)},
+			input: &examplePrecedenceConfig{},
+			expectedResult: &examplePrecedenceConfig{
+				Host:     "env.internal",
+				Port:     3308,
+				User:     "admin",
+				Password: "env",
+			},
+			clean: func() {
+				os.Unsetenv("MY_HOST")
+				os.Unsetenv("MY_PORT")
+				os.Unsetenv("MY_USER")
+				os.Unsetenv("MY_PASSWORD")
+			},
+		},
+		"kv beats env": {
+			prep: func() {
+				os.Setenv("MY_HOST", "env.internal")
+			},
+			opts:  []environ.Option{environ.WithSources(kv, environ.EnvSource())},
+			input: &examplePrecedenceConfig{},
+			expectedResult: &examplePrecedenceConfig{
+				Host:     "kv.internal",
+				Port:     3308,
+				User:     "admin",
+				Password: "",
+			},
+			clean: func() {
+				os.Unsetenv("MY_HOST")
+			},
+		},
+		"default beats kv": {
+			opts:  []environ.Option{environ.WithSources(environ.DefaultSource(), kv)},
+			input: &examplePrecedenceConfig{},
+			expectedResult: &examplePrecedenceConfig{
+				Host: "localhost",
+				Port: 3308,
+				User: "admin",
+			},
+		},
+		"defaults do not satisfy required fields": {
+			opts:  []environ.Option{environ.WithSources(environ.DefaultSource(), mapSource{tag: "kv"})},
+			input: &exampleMultiSourceConfig{},
+			expectedError: environ.EnvError{
+				Err:    environ.ErrRequiredNotFound,
+				Key:    "Password",
+				Source: "kv",
+				Extra:  "required field not loaded",
+			},
+		},
+		"required fields check sources after a leading default": {
+			prep: func() {
+				os.Setenv("MY_HOST", "env.internal")
+			},
+			opts:  []environ.Option{environ.WithSources(environ.DefaultSource(), environ.EnvSource())},
+			input: &exampleRequiredDefaultConfig{},
+			expectedResult: &exampleRequiredDefaultConfig{
+				Host: "env.internal",
+			},
+			clean: func() {
+				os.Unsetenv("MY_HOST")
+			},
+		},
+		"leading default does not satisfy required fields": {
+			opts:  []environ.Option{environ.WithSources(environ.DefaultSource(), environ.EnvSource())},
+			input: &exampleRequiredDefaultConfig{},
+			expectedError: environ.EnvError{
+				Err:    environ.ErrRequiredNotFound,
+				Key:    "Host",
+				Source: "env",
+				Extra:  "required field not loaded",
+			},
+		},
+		"error reports the source that supplied a bad value": {
+			prep: func() {
+				os.Setenv("MY_PORT", "not a port")
+			},
+			opts:  []environ.Option{environ.WithSources(environ.EnvSource(), kv)},
+			input: &exampleMultiSourceConfig{},
+			expectedError: environ.EnvError{
+				Err:    environ.ErrInvalidFormat,
+				Key:    "Port",
+				Source: "env",
+				Extra:  "value is not a valid integer representation",
+			},
+			clean: func() {
+				os.Unsetenv("MY_PORT")
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// prep the test
+			if tc.prep != nil {
+				tc.prep()
+			}
+			// defer the clean up
+			if tc.clean != nil {
+				defer tc.clean()
+			}
+			// run the test
+			err := environ.Load(tc.input, tc.opts...)
+			// validate error
+			var envErr *environ.EnvError
+			if errors.As(err, &envErr) {
+				if tc.expectedError != *envErr {
+					slog.Error("expected error didn't match error", "expected error", tc.expectedError, "error", *envErr)
+					t.FailNow()
+					return
+				}
+			}
+			if err == nil && tc.expectedError.Err != nil {
+				slog.Error("no error occured where an error was expected", "expected error", tc.expectedError)
+				t.FailNow()
+				return
+			}
+
+			// done checking if this should have errored
+			if tc.expectedError.Err != nil {
+				return
+			}
+
+			// validate result
+			if !reflect.DeepEqual(tc.input, tc.expectedResult) {
+				slog.Error("expected result does not match result", "expected result", tc.expectedResult, "result", tc.input)
+				t.Fail()
+				return
+			}
+		})
+	}
+}
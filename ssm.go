@@ -0,0 +1,105 @@
+package environ
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// ssmBatchSize is the max number of names accepted by a single GetParameters call
+const ssmBatchSize = 10
+
+// SSMClient is the subset of the AWS SSM client used to read parameters
+type SSMClient interface {
+	GetParameters(ctx context.Context, params *ssm.GetParametersInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersOutput, error)
+}
+
+// SSMSource reads values from AWS Systems Manager Parameter Store by `ssm` tags, IE: `ssm:"/prod/db/password"`.
+// SecureString parameters are decrypted, and parameters are fetched in batches when the source is preloaded.
+type SSMSource struct {
+	client SSMClient
+
+	mu      sync.Mutex
+	values  map[string]string
+	missing map[string]bool
+}
+
+// NewSSMSource creates an SSMSource from an AWS config. The endpoint overrides the default AWS endpoint when it is not
+// empty, IE: http://localhost:4566 for LocalStack.
+func NewSSMSource(cfg aws.Config, endpoint string) *SSMSource {
+	client := ssm.NewFromConfig(cfg, func(o *ssm.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+	return NewSSMSourceFromClient(client)
+}
+
+// NewSSMSourceFromClient creates an SSMSource using an existing client
+func NewSSMSourceFromClient(client SSMClient) *SSMSource {
+	return &SSMSource{
+		client:  client,
+		values:  map[string]string{},
+		missing: map[string]bool{},
+	}
+}
+
+// Tag returns the ssm tag
+func (s *SSMSource) Tag() string {
+	return ssmTag
+}
+
+// Preload clears any previously fetched parameters and fetches the given names in batches
+func (s *SSMSource) Preload(keys []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = map[string]string{}
+	s.missing = map[string]bool{}
+	for start := 0; start < len(keys); start += ssmBatchSize {
+		end := min(start+ssmBatchSize, len(keys))
+		err := s.fetch(keys[start:end])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Lookup returns the parameter stored under key, fetching it when it was not preloaded
+func (s *SSMSource) Lookup(key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.missing[key] {
+		if _, ok := s.values[key]; !ok {
+			err := s.fetch([]string{key})
+			if err != nil {
+				return "", false, err
+			}
+		}
+	}
+	v, ok := s.values[key]
+	return v, ok, nil
+}
+
+// fetches a single batch of parameters, recording any names that do not exist
+func (s *SSMSource) fetch(names []string) error {
+	output, err := s.client.GetParameters(context.Background(), &ssm.GetParametersInput{
+		Names:          names,
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return err
+	}
+	for _, param := range output.Parameters {
+		// version and label selectors are returned separately from the name
+		s.values[aws.ToString(param.Name)+aws.ToString(param.Selector)] = aws.ToString(param.Value)
+	}
+	for _, name := range names {
+		if _, ok := s.values[name]; !ok {
+			s.missing[name] = true
+		}
+	}
+	return nil
+}
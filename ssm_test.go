@@ -0,0 +1,188 @@
+package environ_test
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+
+	"github.com/NeedMoreVolume/environ"
+)
+
+// fakeSSM mimics the GetParameters action of the SSM JSON API
+type fakeSSM struct {
+	mu         sync.Mutex
+	params     map[string]string
+	calls      [][]string
+	decryption []bool
+}
+
+func (f *fakeSSM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Amz-Target") != "AmazonSSM.GetParameters" {
+		http.Error(w, "unsupported action", http.StatusBadRequest)
+		return
+	}
+	var input struct {
+		Names          []string
+		WithDecryption bool
+	}
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	f.calls = append(f.calls, input.Names)
+	f.decryption = append(f.decryption, input.WithDecryption)
+	f.mu.Unlock()
+
+	type parameter struct {
+		Name  string
+		Type  string
+		Value string
+	}
+	output := struct {
+		Parameters        []parameter
+		InvalidParameters []string
+	}{
+		Parameters:        []parameter{},
+		InvalidParameters: []string{},
+	}
+	for _, name := range input.Names {
+		if v, ok := f.params[name]; ok {
+			output.Parameters = append(output.Parameters, parameter{Name: name, Type: "SecureString", Value: v})
+			continue
+		}
+		output.InvalidParameters = append(output.InvalidParameters, name)
+	}
+	w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+	_ = json.NewEncoder(w).Encode(output)
+}
+
+func testAWSConfig() aws.Config {
+	return aws.Config{
+		Region:           "us-east-1",
+		Credentials:      credentials.NewStaticCredentialsProvider("test", "test", ""),
+		RetryMaxAttempts: 1,
+	}
+}
+
+type exampleSSMConfig struct {
+	Username string `ssm:"/prod/db/username" required:"true"`
+	Password string `ssm:"/prod/db/password" required:"true"`
+	Host     string `env:"MY_HOST" ssm:"/prod/db/host" default:"localhost"`
+	Port     int    `ssm:"/prod/db/port" default:"3306"`
+	Nested   struct {
+		A string `ssm:"/prod/a"`
+		B string `ssm:"/prod/b"`
+		C string `ssm:"/prod/c"`
+		D string `ssm:"/prod/d"`
+		E string `ssm:"/prod/e"`
+		F string `ssm:"/prod/f"`
+		G string `ssm:"/prod/g"`
+		H string `ssm:"/prod/h"`
+	}
+}
+
+func TestSSMSource(t *testing.T) {
+	fake := &fakeSSM{
+		params: map[string]string{
+			"/prod/db/username": "admin",
+			"/prod/db/password": "hunter2",
+			"/prod/db/port":     "3307",
+			"/prod/a":           "a",
+			"/prod/h":           "h",
+		},
+	}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	var (
+		source = environ.NewSSMSource(testAWSConfig(), server.URL)
+		cfg    exampleSSMConfig
+	)
+	err := environ.LoadWith(&cfg, environ.EnvSource(), source)
+	if err != nil {
+		slog.Error("failed to load ssm config", "error", err)
+		t.FailNow()
+	}
+
+	expected := exampleSSMConfig{
+		Username: "admin",
+		Password: "hunter2",
+		Host:     "localhost",
+		Port:     3307,
+	}
+	expected.Nested.A = "a"
+	expected.Nested.H = "h"
+	if !reflect.DeepEqual(cfg, expected) {
+		slog.Error("expected result does not match result", "expected result", expected, "result", cfg)
+		t.Fail()
+	}
+
+	// 12 unique names should be fetched in two batches with no follow up calls for missing names
+	if len(fake.calls) != 2 || len(fake.calls[0]) != 10 || len(fake.calls[1]) != 2 {
+		slog.Error("parameters were not fetched in batches", "calls", fake.calls)
+		t.Fail()
+	}
+	for _, decryption := range fake.decryption {
+		if !decryption {
+			slog.Error("parameters were fetched without decryption")
+			t.Fail()
+		}
+	}
+}
+
+func TestSSMSourceLookup(t *testing.T) {
+	fake := &fakeSSM{params: map[string]string{"/prod/db/password": "hunter2"}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	source := environ.NewSSMSource(testAWSConfig(), server.URL)
+	// without preloading, values are fetched on demand
+	v, ok, err := source.Lookup("/prod/db/password")
+	if err != nil || !ok || v != "hunter2" {
+		slog.Error("failed to look up parameter", "found", ok, "error", err)
+		t.Fail()
+	}
+	_, ok, err = source.Lookup("/prod/db/missing")
+	if err != nil || ok {
+		slog.Error("missing parameter was found", "found", ok, "error", err)
+		t.Fail()
+	}
+	// repeated lookups are served from the cache
+	_, _, _ = source.Lookup("/prod/db/password")
+	_, _, _ = source.Lookup("/prod/db/missing")
+	if len(fake.calls) != 2 {
+		slog.Error("lookups were not cached", "calls", fake.calls)
+		t.Fail()
+	}
+}
+
+func TestSSMSourceError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "unavailable", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	var cfg exampleSSMConfig
+	err := environ.LoadWith(&cfg, environ.NewSSMSource(testAWSConfig(), server.URL))
+	var envErr *environ.EnvError
+	if !errors.As(err, &envErr) || envErr.Err != environ.ErrLoading || envErr.Key != "ssm" {
+		slog.Error("expected a loading error", "error", err)
+		t.Fail()
+	}
+	// preload failures are collected like field failures
+	var multiErr *environ.MultiError
+	if !errors.As(err, &multiErr) || len(multiErr.Errors) != 1 {
+		slog.Error("expected a MultiError with the preload error", "error", err)
+		t.Fail()
+	}
+}
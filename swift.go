@@ -0,0 +1,251 @@
+package environ
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	// header keystone returns the token in
+	swiftSubjectTokenHeader = "X-Subject-Token"
+	// header swift expects the token in
+	swiftAuthTokenHeader = "X-Auth-Token"
+	// service catalog type for swift endpoints
+	swiftServiceType = "object-store"
+	// default domain for keystone users and projects
+	defaultSwiftDomain = "Default"
+)
+
+var (
+	// errSwiftKey is returned for swift tags that are not in the container/object format
+	errSwiftKey = errors.New("swift tag must be in the container/object format")
+	// errSwiftAuth is returned when keystone does not issue a token
+	errSwiftAuth = errors.New("failed to authenticate with keystone")
+	// errSwiftNoEndpoint is returned when the service catalog does not contain a usable object-store endpoint
+	errSwiftNoEndpoint = errors.New("no object-store endpoint found in the service catalog")
+	// errSwiftStatus is returned for unexpected responses from swift
+	errSwiftStatus = errors.New("unexpected response from swift")
+)
+
+// SwiftConfig configures the Keystone authentication and endpoint used by a SwiftSource
+type SwiftConfig struct {
+	// AuthURL is the Keystone v3 identity endpoint, IE: https://keystone.example.com/v3
+	AuthURL string
+	// Username and Password of the Keystone user
+	Username string
+	Password string
+	// UserDomain of the Keystone user, defaults to Default
+	UserDomain string
+	// Project the token is scoped to
+	Project string
+	// ProjectDomain of the project, defaults to Default
+	ProjectDomain string
+	// Region selects the object-store endpoint from the service catalog, any region is used when empty
+	Region string
+	// StorageURL overrides the object-store endpoint from the service catalog when it is not empty
+	StorageURL string
+	// Client is used for all requests, defaults to http.DefaultClient
+	Client *http.Client
+}
+
+// SwiftSource reads values from objects in OpenStack Swift by `swift` tags, IE: `swift:"config/db_password"`. The first
+// path segment of a tag is the container and the rest is the object name. Each object is fetched once per load.
+type SwiftSource struct {
+	cfg SwiftConfig
+
+	mu         sync.Mutex
+	token      string
+	storageURL string
+	objects    map[string]*string
+}
+
+// NewSwiftSource creates a SwiftSource, authentication is deferred until the first object is fetched
+func NewSwiftSource(cfg SwiftConfig) *SwiftSource {
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	if cfg.UserDomain == "" {
+		cfg.UserDomain = defaultSwiftDomain
+	}
+	if cfg.ProjectDomain == "" {
+		cfg.ProjectDomain = defaultSwiftDomain
+	}
+	return &SwiftSource{
+		cfg:     cfg,
+		objects: map[string]*string{},
+	}
+}
+
+// Tag returns the swift tag
+func (s *SwiftSource) Tag() string {
+	return swiftTag
+}
+
+// Preload clears any previously fetched objects so values are refreshed on every load
+func (s *SwiftSource) Preload(_ []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects = map[string]*string{}
+	return nil
+}
+
+// Lookup returns the contents of the object named by key
+func (s *SwiftSource) Lookup(key string) (string, bool, error) {
+	container, object, found := strings.Cut(key, "/")
+	if !found || container == "" || object == "" {
+		return "", false, errSwiftKey
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.objects[key]
+	if !ok {
+		var err error
+		value, err = s.fetch(container, object)
+		if err != nil {
+			return "", false, err
+		}
+		s.objects[key] = value
+	}
+	if value == nil {
+		return "", false, nil
+	}
+	return *value, true, nil
+}
+
+// fetches an object, authenticating first when there is no token or the token has expired. Objects that do not exist
+// are returned as nil.
+func (s *SwiftSource) fetch(container, object string) (*string, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		if s.token == "" {
+			err := s.authenticate()
+			if err != nil {
+				return nil, err
+			}
+		}
+		req, err := http.NewRequest(http.MethodGet, s.storageURL+"/"+url.PathEscape(container)+"/"+escapeSwiftObject(object), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(swiftAuthTokenHeader, s.token)
+		resp, err := s.cfg.Client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		switch resp.StatusCode {
+		case http.StatusOK:
+			value := string(body)
+			return &value, nil
+		case http.StatusNotFound:
+			return nil, nil
+		case http.StatusUnauthorized:
+			// the token expired or was revoked, get a new one and retry
+			s.token = ""
+		default:
+			return nil, fmt.Errorf("%w: %s", errSwiftStatus, resp.Status)
+		}
+	}
+	return nil, errSwiftAuth
+}
+
+// requests a project scoped token from keystone using password auth, and finds the storage url in the catalog
+func (s *SwiftSource) authenticate() error {
+	type domain struct {
+		Name string `json:"name"`
+	}
+	var request struct {
+		Auth struct {
+			Identity struct {
+				Methods  []string `json:"methods"`
+				Password struct {
+					User struct {
+						Name     string `json:"name"`
+						Domain   domain `json:"domain"`
+						Password string `json:"password"`
+					} `json:"user"`
+				} `json:"password"`
+			} `json:"identity"`
+			Scope struct {
+				Project struct {
+					Name   string `json:"name"`
+					Domain domain `json:"domain"`
+				} `json:"project"`
+			} `json:"scope"`
+		} `json:"auth"`
+	}
+	request.Auth.Identity.Methods = []string{"password"}
+	request.Auth.Identity.Password.User.Name = s.cfg.Username
+	request.Auth.Identity.Password.User.Domain.Name = s.cfg.UserDomain
+	request.Auth.Identity.Password.User.Password = s.cfg.Password
+	request.Auth.Scope.Project.Name = s.cfg.Project
+	request.Auth.Scope.Project.Domain.Name = s.cfg.ProjectDomain
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.cfg.Client.Post(strings.TrimSuffix(s.cfg.AuthURL, "/")+"/auth/tokens", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	token := resp.Header.Get(swiftSubjectTokenHeader)
+	if resp.StatusCode != http.StatusCreated || token == "" {
+		return fmt.Errorf("%w: %s", errSwiftAuth, resp.Status)
+	}
+	storageURL := s.cfg.StorageURL
+	if storageURL == "" {
+		var output struct {
+			Token struct {
+				Catalog []struct {
+					Type      string `json:"type"`
+					Endpoints []struct {
+						Interface string `json:"interface"`
+						Region    string `json:"region"`
+						URL       string `json:"url"`
+					} `json:"endpoints"`
+				} `json:"catalog"`
+			} `json:"token"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&output)
+		if err != nil {
+			return err
+		}
+		for _, service := range output.Token.Catalog {
+			if service.Type != swiftServiceType {
+				continue
+			}
+			for _, endpoint := range service.Endpoints {
+				if storageURL == "" && endpoint.Interface == "public" && (s.cfg.Region == "" || endpoint.Region == s.cfg.Region) {
+					storageURL = endpoint.URL
+				}
+			}
+		}
+		if storageURL == "" {
+			return errSwiftNoEndpoint
+		}
+	}
+	s.token = token
+	s.storageURL = strings.TrimSuffix(storageURL, "/")
+	return nil
+}
+
+// escapes each segment of an object name, keeping the slashes used for pseudo directories
+func escapeSwiftObject(object string) string {
+	segments := strings.Split(object, "/")
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,175 @@
+package environ_test
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/NeedMoreVolume/environ"
+)
+
+// fakeSwift mimics keystone token auth and object reads from the swift API
+type fakeSwift struct {
+	mu      sync.Mutex
+	server  *httptest.Server
+	token   string
+	objects map[string]string
+	auths   int
+	reads   int
+}
+
+func newFakeSwift(objects map[string]string) *fakeSwift {
+	f := &fakeSwift{objects: objects}
+	f.server = httptest.NewServer(f)
+	return f
+}
+
+func (f *fakeSwift) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Method == http.MethodPost && r.URL.Path == "/v3/auth/tokens" {
+		var input struct {
+			Auth struct {
+				Identity struct {
+					Password struct {
+						User struct {
+							Name     string `json:"name"`
+							Password string `json:"password"`
+						} `json:"user"`
+					} `json:"password"`
+				} `json:"identity"`
+			} `json:"auth"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&input)
+		if input.Auth.Identity.Password.User.Name != "admin" || input.Auth.Identity.Password.User.Password != "hunter2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.auths++
+		f.token = "token-" + strings.Repeat("x", f.auths)
+		w.Header().Set("X-Subject-Token", f.token)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"token": map[string]any{
+				"catalog": []map[string]any{
+					{"type": "identity", "endpoints": []map[string]string{{"interface": "public", "region": "east", "url": f.server.URL + "/v3"}}},
+					{"type": "object-store", "endpoints": []map[string]string{
+						{"interface": "internal", "region": "east", "url": f.server.URL + "/internal"},
+						{"interface": "public", "region": "west", "url": f.server.URL + "/west"},
+						{"interface": "public", "region": "east", "url": f.server.URL + "/v1/AUTH_test"},
+					}},
+				},
+			},
+		})
+		return
+	}
+	if r.Header.Get("X-Auth-Token") != f.token {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	object, found := strings.CutPrefix(r.URL.Path, "/v1/AUTH_test/")
+	if r.Method != http.MethodGet || !found {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	f.reads++
+	v, ok := f.objects[object]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_, _ = w.Write([]byte(v))
+}
+
+type exampleSwiftConfig struct {
+	Password     string   `swift:"config/db_password" required:"true"`
+	SamePassword string   `swift:"config/db_password"`
+	Hosts        []string `swift:"config/nested/hosts"`
+	Missing      string   `swift:"config/missing" default:"fallback"`
+}
+
+func TestSwiftSource(t *testing.T) {
+	fake := newFakeSwift(map[string]string{
+		"config/db_password":  "hunter2",
+		"config/nested/hosts": "a,b",
+	})
+	defer fake.server.Close()
+
+	source := environ.NewSwiftSource(environ.SwiftConfig{
+		AuthURL:  fake.server.URL + "/v3",
+		Username: "admin",
+		Password: "hunter2",
+		Project:  "test",
+		Region:   "east",
+	})
+	var cfg exampleSwiftConfig
+	err := environ.LoadWith(&cfg, source)
+	if err != nil {
+		slog.Error("failed to load swift config", "error", err)
+		t.FailNow()
+	}
+	expected := exampleSwiftConfig{
+		Password:     "hunter2",
+		SamePassword: "hunter2",
+		Hosts:        []string{"a", "b"},
+		Missing:      "fallback",
+	}
+	if !reflect.DeepEqual(cfg, expected) {
+		slog.Error("expected result does not match result", "expected result", expected, "result", cfg)
+		t.Fail()
+	}
+	// each object is read once per load
+	if fake.auths != 1 || fake.reads != 3 {
+		slog.Error("objects were not cached", "auths", fake.auths, "reads", fake.reads)
+		t.Fail()
+	}
+
+	// an expired token is replaced and the next load reads objects again
+	fake.token = "expired"
+	err = environ.LoadWith(&cfg, source)
+	if err != nil || fake.auths != 2 || fake.reads != 6 {
+		slog.Error("objects were not refreshed", "auths", fake.auths, "reads", fake.reads, "error", err)
+		t.Fail()
+	}
+}
+
+func TestSwiftSourceErrors(t *testing.T) {
+	fake := newFakeSwift(map[string]string{})
+	defer fake.server.Close()
+
+	testCases := map[string]struct {
+		cfg environ.SwiftConfig
+		key string
+	}{
+		"key without an object": {
+			cfg: environ.SwiftConfig{AuthURL: fake.server.URL + "/v3", Username: "admin", Password: "hunter2"},
+			key: "config",
+		},
+		"bad credentials": {
+			cfg: environ.SwiftConfig{AuthURL: fake.server.URL + "/v3", Username: "admin", Password: "wrong"},
+			key: "config/db_password",
+		},
+		"no endpoint for region": {
+			cfg: environ.SwiftConfig{AuthURL: fake.server.URL + "/v3", Username: "admin", Password: "hunter2", Region: "north"},
+			key: "config/db_password",
+		},
+		"unexpected response": {
+			cfg: environ.SwiftConfig{AuthURL: fake.server.URL + "/v3", Username: "admin", Password: "hunter2", StorageURL: fake.server.URL + "/unknown"},
+			key: "config/db_password",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := environ.NewSwiftSource(tc.cfg).Lookup(tc.key)
+			if err == nil {
+				slog.Error("expected an error looking up object")
+				t.Fail()
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package environ
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// layouts for epoch timestamps in layout tags
+const (
+	unixLayout      = "unix"
+	unixMilliLayout = "unixmilli"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	locationType = reflect.TypeOf(time.Location{})
+
+	// names of the layouts from the time package that can be used in layout tags, IE: `layout:"DateOnly"`
+	namedLayouts = map[string]string{
+		"ANSIC":       time.ANSIC,
+		"UnixDate":    time.UnixDate,
+		"RubyDate":    time.RubyDate,
+		"RFC822":      time.RFC822,
+		"RFC822Z":     time.RFC822Z,
+		"RFC850":      time.RFC850,
+		"RFC1123":     time.RFC1123,
+		"RFC1123Z":    time.RFC1123Z,
+		"RFC3339":     time.RFC3339,
+		"RFC3339Nano": time.RFC3339Nano,
+		"Kitchen":     time.Kitchen,
+		"DateTime":    time.DateTime,
+		"DateOnly":    time.DateOnly,
+		"TimeOnly":    time.TimeOnly,
+	}
+)
+
+// checks if a type is loaded as a time, which are structs that are set from a single value
+func isTimeType(t reflect.Type) bool {
+	return t == timeType || t == locationType
+}
+
+// sets a time.Time, time.Location or *time.Location param, returning false when the param is not one of them. Times
+// are parsed with the layout tag, which is a layout for time.Parse, the name of a layout from the time package, or
+// unix and unixmilli for epoch seconds and milliseconds. Locations are loaded with time.LoadLocation.
+func setTimeValue(structField reflect.StructField, param reflect.Value, value string) (bool, error) {
+	switch param.Type() {
+	case timeType:
+		t, err := parseTime(structField.Tag.Get(layoutTag), value)
+		if err != nil {
+			return true, newError(ErrInvalidFormat, structField.Name, "value is not a valid time for the layout")
+		}
+		param.Set(reflect.ValueOf(t))
+	case locationType, reflect.PointerTo(locationType):
+		loc, err := time.LoadLocation(value)
+		if err != nil {
+			return true, newError(ErrInvalidFormat, structField.Name, "value is not a valid time zone")
+		}
+		if param.Kind() == reflect.Ptr {
+			param.Set(reflect.ValueOf(loc))
+		} else {
+			param.Set(reflect.ValueOf(loc).Elem())
+		}
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// parses a time with a layout, using RFC3339 when the layout is empty
+func parseTime(layout, value string) (time.Time, error) {
+	switch layout {
+	case "":
+		layout = time.RFC3339
+	case unixLayout, unixMilliLayout:
+		epoch, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if layout == unixLayout {
+			return time.Unix(epoch, 0).UTC(), nil
+		}
+		return time.UnixMilli(epoch).UTC(), nil
+	}
+	if named, ok := namedLayouts[layout]; ok {
+		layout = named
+	}
+	return time.Parse(layout, value)
+}
@@ -0,0 +1,103 @@
+package environ_test
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/NeedMoreVolume/environ"
+)
+
+type exampleTimeConfig struct {
+	Cutover   time.Time      `env:"TIME_CUTOVER"`
+	Date      time.Time      `env:"TIME_DATE" layout:"DateOnly"`
+	Custom    time.Time      `env:"TIME_CUSTOM" layout:"02/01/2006 15:04"`
+	Epoch     time.Time      `env:"TIME_EPOCH" layout:"unix"`
+	EpochMs   time.Time      `env:"TIME_EPOCH_MS" layout:"unixmilli"`
+	Holidays  []time.Time    `env:"TIME_HOLIDAYS" layout:"DateOnly"`
+	Started   *time.Time     `env:"TIME_STARTED"`
+	Unset     *time.Time     `env:"TIME_UNSET"`
+	Zone      *time.Location `env:"TIME_ZONE" default:"UTC"`
+	OtherZone time.Location  `env:"TIME_OTHER_ZONE"`
+}
+
+func TestTimes(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database is not available")
+	}
+	t.Setenv("TIME_CUTOVER", "2024-03-01T12:30:00+02:00")
+	t.Setenv("TIME_DATE", "2024-03-01")
+	t.Setenv("TIME_CUSTOM", "25/12/2024 08:15")
+	t.Setenv("TIME_EPOCH", "1700000000")
+	t.Setenv("TIME_EPOCH_MS", "1700000000123")
+	t.Setenv("TIME_HOLIDAYS", "2024-12-25,2025-01-01")
+	t.Setenv("TIME_STARTED", "2024-01-01T00:00:00Z")
+	t.Setenv("TIME_OTHER_ZONE", "America/New_York")
+
+	var cfg exampleTimeConfig
+	err = environ.Load(&cfg)
+	if err != nil {
+		slog.Error("failed to load time config", "error", err)
+		t.FailNow()
+	}
+	started := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	checks := map[string]bool{
+		"cutover":    cfg.Cutover.Equal(time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)),
+		"date":       cfg.Date.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)),
+		"custom":     cfg.Custom.Equal(time.Date(2024, 12, 25, 8, 15, 0, 0, time.UTC)),
+		"epoch":      cfg.Epoch.Equal(time.Unix(1700000000, 0)),
+		"epoch ms":   cfg.EpochMs.Equal(time.UnixMilli(1700000000123)),
+		"holidays":   len(cfg.Holidays) == 2 && cfg.Holidays[1].Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+		"started":    cfg.Started != nil && cfg.Started.Equal(started),
+		"unset":      cfg.Unset == nil,
+		"zone":       cfg.Zone == time.UTC,
+		"other zone": cfg.OtherZone.String() == newYork.String(),
+	}
+	for name, ok := range checks {
+		if !ok {
+			slog.Error("time was not loaded", "field", name, "result", cfg)
+			t.Fail()
+		}
+	}
+}
+
+func TestTimeErrors(t *testing.T) {
+	testCases := map[string]struct {
+		key           string
+		value         string
+		expectedError environ.EnvError
+	}{
+		"invalid time": {
+			key:           "TIME_CUTOVER",
+			value:         "2024-03-01",
+			expectedError: environ.EnvError{Err: environ.ErrInvalidFormat, Key: "Cutover", Source: "env", Extra: "value is not a valid time for the layout"},
+		},
+		"invalid epoch": {
+			key:           "TIME_EPOCH",
+			value:         "yesterday",
+			expectedError: environ.EnvError{Err: environ.ErrInvalidFormat, Key: "Epoch", Source: "env", Extra: "value is not a valid time for the layout"},
+		},
+		"invalid zone": {
+			key:           "TIME_ZONE",
+			value:         "Mars/Olympus_Mons",
+			expectedError: environ.EnvError{Err: environ.ErrInvalidFormat, Key: "Zone", Source: "env", Extra: "value is not a valid time zone"},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(tc.key, tc.value)
+			var (
+				cfg    exampleTimeConfig
+				envErr *environ.EnvError
+			)
+			err := environ.Load(&cfg)
+			if !errors.As(err, &envErr) || !reflect.DeepEqual(*envErr, tc.expectedError) {
+				slog.Error("expected error didn't match error", "expected error", tc.expectedError, "error", err)
+				t.Fail()
+			}
+		})
+	}
+}
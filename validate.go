@@ -0,0 +1,300 @@
+package environ
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// formats supported by the format tag
+const (
+	urlFormat      = "url"
+	hostportFormat = "hostport"
+	emailFormat    = "email"
+)
+
+// validates a loaded field against its validation tags. Length tags check the length of strings, slices and maps, and
+// the other tags check each element of slices. Secrets and pointers are validated by the value they hold.
+func validateField(structField reflect.StructField, param reflect.Value) error {
+	if param.CanAddr() {
+		if secret, ok := param.Addr().Interface().(secretWrapper); ok {
+			param = secret.secretValue()
+		}
+	}
+	if param.Kind() == reflect.Ptr {
+		if param.IsNil() {
+			return nil
+		}
+		param = param.Elem()
+	}
+	err := validateLength(structField, param)
+	if err != nil {
+		return err
+	}
+	if param.Kind() == reflect.Slice && !isDecodable(param.Type()) {
+		for i := 0; i < param.Len(); i++ {
+			err = validateValue(structField, param.Index(i))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return validateValue(structField, param)
+}
+
+// validates the len, minlen and maxlen tags. Strings are measured in characters, slices and maps in elements.
+func validateLength(structField reflect.StructField, param reflect.Value) error {
+	var length int
+	switch param.Kind() {
+	case reflect.String:
+		length = utf8.RuneCountInString(param.String())
+	case reflect.Slice, reflect.Map:
+		length = param.Len()
+	default:
+		return nil
+	}
+	for _, rule := range []struct {
+		tag   string
+		check func(limit int) bool
+		extra string
+	}{
+		{lenTag, func(limit int) bool { return length == limit }, "value length does not match len tag"},
+		{minLenTag, func(limit int) bool { return length >= limit }, "value is shorter than minlen tag"},
+		{maxLenTag, func(limit int) bool { return length <= limit }, "value is longer than maxlen tag"},
+	} {
+		t, found := structField.Tag.Lookup(rule.tag)
+		if !found {
+			continue
+		}
+		limit, err := strconv.Atoi(t)
+		if err != nil {
+			return newError(ErrInvalidFormat, structField.Name, rule.tag+" tag value is not a valid integer representation")
+		}
+		if !rule.check(limit) {
+			return newError(ErrInvalidLength, structField.Name, rule.extra)
+		}
+	}
+	return nil
+}
+
+// validates the min, max, oneof, regex and format tags for a single value
+func validateValue(structField reflect.StructField, param reflect.Value) error {
+	err := validateRange(structField, param)
+	if err != nil {
+		return err
+	}
+	if t, found := structField.Tag.Lookup(oneofTag); found && isScalar(param) {
+		allowed := false
+		for _, option := range strings.Split(t, ",") {
+			if strings.TrimSpace(option) == scalarString(param) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return newError(ErrNotOneOf, structField.Name, "value is not one of the options in oneof tag")
+		}
+	}
+	if param.Kind() != reflect.String {
+		return nil
+	}
+	if t, found := structField.Tag.Lookup(regexTag); found {
+		pattern, err := regexp.Compile(t)
+		if err != nil {
+			return newError(ErrInvalidFormat, structField.Name, "regex tag value is not a valid regular expression")
+		}
+		if !pattern.MatchString(param.String()) {
+			return newError(ErrPatternMismatch, structField.Name, "value does not match regex tag")
+		}
+	}
+	if t, found := structField.Tag.Lookup(formatTag); found {
+		valid, known := validFormat(t, param.String())
+		if !known {
+			return newError(ErrInvalidFormat, structField.Name, "format tag value is not a supported format")
+		}
+		if !valid {
+			return newError(ErrInvalidFormat, structField.Name, "value is not a valid "+t)
+		}
+	}
+	return nil
+}
+
+// validates the min and max tags for numbers and durations, durations use limits like 5s
+func validateRange(structField reflect.StructField, param reflect.Value) error {
+	for _, rule := range []struct {
+		tag   string
+		check func(value, limit float64) bool
+		extra string
+	}{
+		{minTag, func(value, limit float64) bool { return value >= limit }, "value is less than min tag"},
+		{maxTag, func(value, limit float64) bool { return value <= limit }, "value is greater than max tag"},
+	} {
+		t, found := structField.Tag.Lookup(rule.tag)
+		if !found {
+			continue
+		}
+		var (
+			value float64
+			limit float64
+			err   error
+		)
+		switch param.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			value = float64(param.Int())
+			if param.Type() == reflect.TypeOf(time.Duration(0)) && strings.ContainsAny(t, durationUnits) {
+				var dur time.Duration
+				dur, err = time.ParseDuration(t)
+				limit = float64(dur)
+			} else {
+				limit, err = strconv.ParseFloat(t, 64)
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			value = float64(param.Uint())
+			limit, err = strconv.ParseFloat(t, 64)
+		case reflect.Float32, reflect.Float64:
+			value = param.Float()
+			limit, err = strconv.ParseFloat(t, 64)
+		default:
+			continue
+		}
+		if err != nil {
+			return newError(ErrInvalidFormat, structField.Name, rule.tag+" tag value is not a valid number or duration representation")
+		}
+		if !rule.check(value, limit) {
+			return newError(ErrOutOfRange, structField.Name, rule.extra)
+		}
+	}
+	return nil
+}
+
+// checks a value against a format, and whether the format is known
+func validFormat(format, value string) (bool, bool) {
+	switch format {
+	case urlFormat:
+		u, err := url.ParseRequestURI(value)
+		return err == nil && u.Scheme != "" && u.Host != "", true
+	case hostportFormat:
+		_, port, err := net.SplitHostPort(value)
+		if err != nil {
+			return false, true
+		}
+		_, err = strconv.ParseUint(port, 10, 16)
+		return err == nil, true
+	case emailFormat:
+		address, err := mail.ParseAddress(value)
+		return err == nil && address.Address == value, true
+	}
+	return false, false
+}
+
+// checks if a value is a string, bool or number that oneof can compare
+func isScalar(param reflect.Value) bool {
+	switch param.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// formats a scalar value for comparing against oneof options, durations are formatted like 5s
+func scalarString(param reflect.Value) string {
+	if param.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(param.Int()).String()
+	}
+	switch param.Kind() {
+	case reflect.String:
+		return param.String()
+	case reflect.Bool:
+		return strconv.FormatBool(param.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(param.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(param.Uint(), 10)
+	}
+	return strconv.FormatFloat(param.Float(), 'g', -1, param.Type().Bits())
+}
+
+// Validator is implemented by config structs with rules that span fields, such as a minimum that must not be greater
+// than a maximum. Validate is called on the config and each of its nested structs once their fields are loaded.
+type Validator interface {
+	Validate() error
+}
+
+// checks the required_if tags of a loaded struct, then calls its Validate method. Validate is skipped when any field
+// of the struct failed to load, so it never sees a partially loaded struct. Errors from Validate are keyed by the path
+// of the struct, IE: Database.Replica.
+func (l *loader) validateStruct(input reflect.Value, sc scope, loadedFields map[string]bool, errs int) {
+	inputType := input.Type()
+	for i := 0; i < inputType.NumField(); i++ {
+		structField := inputType.Field(i)
+		condition, found := structField.Tag.Lookup(requiredIfTag)
+		if !found {
+			continue
+		}
+		required, extra := conditionMet(input, condition)
+		if extra != "" {
+			l.addError(newError(ErrInvalidFormat, structField.Name, extra))
+			continue
+		}
+		if required && !loadedFields[structField.Name] {
+			l.addError(newSourceError(ErrRequiredNotFound, structField.Name, l.sourceTags(structField, l.fieldSources(structField)), "required field not loaded because of required_if tag"))
+		}
+	}
+	// the Validate method of an embedded struct is promoted and called for the struct embedding it
+	if len(l.errs) > errs || sc.embedded {
+		return
+	}
+	validator, ok := input.Addr().Interface().(Validator)
+	if !ok {
+		return
+	}
+	err := validator.Validate()
+	if err == nil {
+		return
+	}
+	key := strings.Join(sc.path, ".")
+	if key == "" {
+		key = inputType.Name()
+	}
+	envErr := newError(err, key, "struct failed validation")
+	l.errs = append(l.errs, envErr)
+	l.validationErrs[envErr] = true
+}
+
+// checks a required_if condition in the Field=value format against the fields of a struct. Returns the extra for an
+// error when the condition is not valid.
+func conditionMet(input reflect.Value, condition string) (bool, string) {
+	name, expected, found := strings.Cut(condition, "=")
+	if !found {
+		return false, "required_if tag value is not in the Field=value format"
+	}
+	field := input.FieldByName(strings.TrimSpace(name))
+	if !field.IsValid() {
+		return false, "required_if tag refers to a field that does not exist"
+	}
+	if field.CanAddr() {
+		if secret, ok := field.Addr().Interface().(secretWrapper); ok {
+			field = secret.secretValue()
+		}
+	}
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return false, ""
+		}
+		field = field.Elem()
+	}
+	if !isScalar(field) {
+		return false, "required_if tag refers to a field that cannot be compared"
+	}
+	return scalarString(field) == strings.TrimSpace(expected), ""
+}
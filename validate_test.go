@@ -0,0 +1,307 @@
+package environ_test
+
+import (
+	"errors"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/NeedMoreVolume/environ"
+)
+
+type exampleValidatedConfig struct {
+	Port     int                    `env:"VALID_PORT" min:"1" max:"65535" default:"8080"`
+	Ratio    float64                `env:"VALID_RATIO" min:"0" max:"1" default:"0.5"`
+	Timeout  time.Duration          `env:"VALID_TIMEOUT" min:"1s" max:"1m" default:"5s"`
+	Workers  *uint                  `env:"VALID_WORKERS" max:"64"`
+	Name     string                 `env:"VALID_NAME" minlen:"3" maxlen:"8" default:"service"`
+	Code     string                 `env:"VALID_CODE" len:"2" default:"us"`
+	Hosts    []string               `env:"VALID_HOSTS" minlen:"1" format:"hostport" default:"a:1,b:2"`
+	Labels   map[string]string      `env:"VALID_LABELS" maxlen:"2" default:"a:1"`
+	Level    string                 `env:"VALID_LEVEL" oneof:"debug,info,warn,error" default:"info"`
+	Retries  int                    `env:"VALID_RETRIES" oneof:"1,3,5" default:"3"`
+	Version  string                 `env:"VALID_VERSION" regex:"^v[0-9]+\\.[0-9]+$" default:"v1.2"`
+	Endpoint string                 `env:"VALID_ENDPOINT" format:"url" default:"https://example.com/api"`
+	Admin    string                 `env:"VALID_ADMIN" format:"email" default:"admin@example.com"`
+	Password environ.Secret[string] `env:"VALID_PASSWORD" minlen:"8" default:"hunter22"`
+	Unset    int                    `env:"VALID_UNSET" min:"1"`
+}
+
+func TestValidation(t *testing.T) {
+	var cfg exampleValidatedConfig
+	err := environ.Load(&cfg)
+	if err != nil {
+		slog.Error("failed to load valid config", "error", err)
+		t.FailNow()
+	}
+
+	testCases := map[string]struct {
+		key           string
+		value         string
+		expectedError environ.EnvError
+	}{
+		"number below min": {
+			key:           "VALID_PORT",
+			value:         "0",
+			expectedError: environ.EnvError{Err: environ.ErrOutOfRange, Key: "Port", Source: "env", Extra: "value is less than min tag"},
+		},
+		"float above max": {
+			key:           "VALID_RATIO",
+			value:         "1.5",
+			expectedError: environ.EnvError{Err: environ.ErrOutOfRange, Key: "Ratio", Source: "env", Extra: "value is greater than max tag"},
+		},
+		"duration above max": {
+			key:           "VALID_TIMEOUT",
+			value:         "2m",
+			expectedError: environ.EnvError{Err: environ.ErrOutOfRange, Key: "Timeout", Source: "env", Extra: "value is greater than max tag"},
+		},
+		"pointer above max": {
+			key:           "VALID_WORKERS",
+			value:         "65",
+			expectedError: environ.EnvError{Err: environ.ErrOutOfRange, Key: "Workers", Source: "env", Extra: "value is greater than max tag"},
+		},
+		"string too short": {
+			key:           "VALID_NAME",
+			value:         "ab",
+			expectedError: environ.EnvError{Err: environ.ErrInvalidLength, Key: "Name", Source: "env", Extra: "value is shorter than minlen tag"},
+		},
+		"string wrong length": {
+			key:           "VALID_CODE",
+			value:         "usa",
+			expectedError: environ.EnvError{Err: environ.ErrInvalidLength, Key: "Code", Source: "env", Extra: "value length does not match len tag"},
+		},
+		"map too long": {
+			key:           "VALID_LABELS",
+			value:         "a:1,b:2,c:3",
+			expectedError: environ.EnvError{Err: environ.ErrInvalidLength, Key: "Labels", Source: "env", Extra: "value is longer than maxlen tag"},
+		},
+		"secret too short": {
+			key:           "VALID_PASSWORD",
+			value:         "short",
+			expectedError: environ.EnvError{Err: environ.ErrInvalidLength, Key: "Password", Source: "env", Extra: "value is shorter than minlen tag"},
+		},
+		"string not one of": {
+			key:           "VALID_LEVEL",
+			value:         "trace",
+			expectedError: environ.EnvError{Err: environ.ErrNotOneOf, Key: "Level", Source: "env", Extra: "value is not one of the options in oneof tag"},
+		},
+		"number not one of": {
+			key:           "VALID_RETRIES",
+			value:         "2",
+			expectedError: environ.EnvError{Err: environ.ErrNotOneOf, Key: "Retries", Source: "env", Extra: "value is not one of the options in oneof tag"},
+		},
+		"pattern mismatch": {
+			key:           "VALID_VERSION",
+			value:         "1.2",
+			expectedError: environ.EnvError{Err: environ.ErrPatternMismatch, Key: "Version", Source: "env", Extra: "value does not match regex tag"},
+		},
+		"invalid url": {
+			key:           "VALID_ENDPOINT",
+			value:         "example.com/api",
+			expectedError: environ.EnvError{Err: environ.ErrInvalidFormat, Key: "Endpoint", Source: "env", Extra: "value is not a valid url"},
+		},
+		"invalid email": {
+			key:           "VALID_ADMIN",
+			value:         "Admin <admin@example.com>",
+			expectedError: environ.EnvError{Err: environ.ErrInvalidFormat, Key: "Admin", Source: "env", Extra: "value is not a valid email"},
+		},
+		"invalid hostport element": {
+			key:           "VALID_HOSTS",
+			value:         "a:1,b",
+			expectedError: environ.EnvError{Err: environ.ErrInvalidFormat, Key: "Hosts", Source: "env", Extra: "value is not a valid hostport"},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(tc.key, tc.value)
+			var (
+				cfg    exampleValidatedConfig
+				envErr *environ.EnvError
+			)
+			err := environ.Load(&cfg)
+			if !errors.As(err, &envErr) || !reflect.DeepEqual(*envErr, tc.expectedError) {
+				slog.Error("expected error didn't match error", "expected error", tc.expectedError, "error", err)
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestValidationTagErrors(t *testing.T) {
+	testCases := map[string]struct {
+		input         any
+		expectedError environ.EnvError
+	}{
+		"invalid min": {
+			input: &struct {
+				Port int `default:"1" min:"one"`
+			}{},
+			expectedError: environ.EnvError{Err: environ.ErrInvalidFormat, Key: "Port", Source: "default", Extra: "min tag value is not a valid number or duration representation"},
+		},
+		"invalid maxlen": {
+			input: &struct {
+				Name string `default:"name" maxlen:"ten"`
+			}{},
+			expectedError: environ.EnvError{Err: environ.ErrInvalidFormat, Key: "Name", Source: "default", Extra: "maxlen tag value is not a valid integer representation"},
+		},
+		"invalid regex": {
+			input: &struct {
+				Name string `default:"name" regex:"("`
+			}{},
+			expectedError: environ.EnvError{Err: environ.ErrInvalidFormat, Key: "Name", Source: "default", Extra: "regex tag value is not a valid regular expression"},
+		},
+		"unknown format": {
+			input: &struct {
+				Name string `default:"name" format:"uuid"`
+			}{},
+			expectedError: environ.EnvError{Err: environ.ErrInvalidFormat, Key: "Name", Source: "default", Extra: "format tag value is not a supported format"},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var envErr *environ.EnvError
+			err := environ.Load(tc.input)
+			if !errors.As(err, &envErr) || !reflect.DeepEqual(*envErr, tc.expectedError) {
+				slog.Error("expected error didn't match error", "expected error", tc.expectedError, "error", err)
+				t.Fail()
+			}
+		})
+	}
+}
+
+var errMinOverMax = errors.New("min conns must not be greater than max conns")
+
+type examplePoolConfig struct {
+	MinConns int `env:"POOL_MIN_CONNS" default:"1"`
+	MaxConns int `env:"POOL_MAX_CONNS" default:"10"`
+}
+
+func (c examplePoolConfig) Validate() error {
+	if c.MinConns > c.MaxConns {
+		return errMinOverMax
+	}
+	return nil
+}
+
+type exampleStructValidatedConfig struct {
+	TLSEnabled bool   `env:"TLS_ENABLED"`
+	TLSCert    string `env:"TLS_CERT" required_if:"TLSEnabled=true"`
+	Mode       string `env:"MODE" default:"dev"`
+	Token      string `env:"TOKEN" required_if:"Mode=prod"`
+	Pool       examplePoolConfig
+	Replica    *examplePoolConfig `envPrefix:"REPLICA_"`
+}
+
+// validated is set when the top level Validate is called
+var validated bool
+
+func (c *exampleStructValidatedConfig) Validate() error {
+	validated = true
+	return nil
+}
+
+func TestStructValidation(t *testing.T) {
+	testCases := map[string]struct {
+		prep          func(t *testing.T)
+		expectedError *environ.EnvError
+	}{
+		"valid": {
+			prep: func(t *testing.T) {},
+		},
+		"required if condition met": {
+			prep: func(t *testing.T) {
+				t.Setenv("TLS_ENABLED", "true")
+			},
+			expectedError: &environ.EnvError{Err: environ.ErrRequiredNotFound, Key: "TLSCert", Source: "env", Extra: "required field not loaded because of required_if tag"},
+		},
+		"required if condition met by a default": {
+			prep: func(t *testing.T) {
+				t.Setenv("MODE", "prod")
+			},
+			expectedError: &environ.EnvError{Err: environ.ErrRequiredNotFound, Key: "Token", Source: "env", Extra: "required field not loaded because of required_if tag"},
+		},
+		"required if condition met and loaded": {
+			prep: func(t *testing.T) {
+				t.Setenv("TLS_ENABLED", "true")
+				t.Setenv("TLS_CERT", "cert.pem")
+			},
+		},
+		"nested struct fails validation": {
+			prep: func(t *testing.T) {
+				t.Setenv("POOL_MIN_CONNS", "20")
+			},
+			expectedError: &environ.EnvError{Err: errMinOverMax, Key: "Pool", Extra: "struct failed validation"},
+		},
+		"configured pointer struct fails validation": {
+			prep: func(t *testing.T) {
+				t.Setenv("REPLICA_POOL_MIN_CONNS", "20")
+			},
+			expectedError: &environ.EnvError{Err: errMinOverMax, Key: "Replica", Extra: "struct failed validation"},
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tc.prep(t)
+			validated = false
+			var (
+				cfg    exampleStructValidatedConfig
+				envErr *environ.EnvError
+			)
+			err := environ.Load(&cfg)
+			if tc.expectedError == nil {
+				if err != nil || !validated {
+					slog.Error("expected config to be valid", "error", err, "validated", validated)
+					t.Fail()
+				}
+				return
+			}
+			if !errors.As(err, &envErr) || !reflect.DeepEqual(envErr, tc.expectedError) || !errors.Is(err, tc.expectedError.Err) {
+				slog.Error("expected error didn't match error", "expected error", tc.expectedError, "error", err)
+				t.Fail()
+			}
+			// Validate is not called when a field fails
+			if validated {
+				slog.Error("top level Validate was called for an invalid config")
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestRequiredIfTagErrors(t *testing.T) {
+	testCases := map[string]struct {
+		input any
+		extra string
+	}{
+		"missing equals": {
+			input: &struct {
+				Cert string `required_if:"TLSEnabled"`
+			}{},
+			extra: "required_if tag value is not in the Field=value format",
+		},
+		"unknown field": {
+			input: &struct {
+				Cert string `required_if:"TLSEnabled=true"`
+			}{},
+			extra: "required_if tag refers to a field that does not exist",
+		},
+		"uncomparable field": {
+			input: &struct {
+				Hosts []string `default:"a"`
+				Cert  string   `required_if:"Hosts=a"`
+			}{},
+			extra: "required_if tag refers to a field that cannot be compared",
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var envErr *environ.EnvError
+			err := environ.Load(tc.input)
+			if !errors.As(err, &envErr) || envErr.Err != environ.ErrInvalidFormat || envErr.Extra != tc.extra {
+				slog.Error("expected error didn't match error", "expected extra", tc.extra, "error", err)
+				t.Fail()
+			}
+		})
+	}
+}
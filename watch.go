@@ -0,0 +1,300 @@
+package environ
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultFilePollInterval is how often watched files are checked for changes
+const defaultFilePollInterval = time.Second
+
+// Change is a single field that changed between loads. Path is the names of the fields leading to the field joined
+// with dots, IE: Database.Host.
+type Change struct {
+	Path string
+	Old  any
+	New  any
+}
+
+// Event is delivered by a Watcher after a reload. Old and New are the snapshots before and after the reload, and
+// Changes lists the fields that differ between them. When a reload fails, Err is set, New is nil and the snapshot is
+// not replaced.
+type Event[T any] struct {
+	Old     *T
+	New     *T
+	Changes []Change
+	Err     error
+}
+
+// WatchOption configures a Watcher
+type WatchOption func(*watchConfig)
+
+// watchConfig holds the options of a Watcher
+type watchConfig struct {
+	loadOpts     []Option
+	interval     time.Duration
+	files        []string
+	pollInterval time.Duration
+	signals      []os.Signal
+}
+
+// WatchLoadOptions sets the options used for every load, IE: WatchLoadOptions(WithSources(EnvSource(), ssmSource))
+func WatchLoadOptions(opts ...Option) WatchOption {
+	return func(c *watchConfig) {
+		c.loadOpts = opts
+	}
+}
+
+// WatchInterval reloads the config every interval
+func WatchInterval(interval time.Duration) WatchOption {
+	return func(c *watchConfig) {
+		c.interval = interval
+	}
+}
+
+// WatchFiles reloads the config when the modification time or size of any of the files changes, such as a config
+// file or a mounted secret. Files are checked every second unless WatchPollInterval says otherwise.
+func WatchFiles(paths ...string) WatchOption {
+	return func(c *watchConfig) {
+		c.files = append(c.files, paths...)
+	}
+}
+
+// WatchPollInterval sets how often the files passed to WatchFiles are checked for changes
+func WatchPollInterval(interval time.Duration) WatchOption {
+	return func(c *watchConfig) {
+		c.pollInterval = interval
+	}
+}
+
+// WatchSignals reloads the config when the process receives any of the signals, IE: WatchSignals(syscall.SIGHUP)
+func WatchSignals(signals ...os.Signal) WatchOption {
+	return func(c *watchConfig) {
+		c.signals = append(c.signals, signals...)
+	}
+}
+
+// Watcher keeps a config of type T loaded, reloading it when one of its triggers fires. Every reload loads into a new
+// value and swaps it in atomically, so readers of Config never see a partially loaded config.
+type Watcher[T any] struct {
+	cfg     watchConfig
+	current atomic.Pointer[T]
+	events  chan Event[T]
+	ctx     context.Context
+
+	// serializes reloads so changes are always diffed against the latest snapshot, and keeps events from being
+	// delivered once the events channel is closed
+	mu sync.Mutex
+}
+
+// Watch loads the config and starts watching for the triggers in the options until the context is done. An error is
+// returned when the first load fails. Events are delivered on the Events channel for every reload that changes the
+// config or fails. Reloads never wait for events to be received. An event that was not received yet is merged with
+// the next one, so it lists every change since the last event that was received, and the channel can be ignored by
+// callers that only read Config.
+func Watch[T any](ctx context.Context, opts ...WatchOption) (*Watcher[T], error) {
+	w := &Watcher[T]{
+		cfg:    watchConfig{pollInterval: defaultFilePollInterval},
+		events: make(chan Event[T], 1),
+		ctx:    ctx,
+	}
+	for _, opt := range opts {
+		opt(&w.cfg)
+	}
+	// files are checked and signals are caught before the first load, so changes made during the load are not missed
+	var (
+		stats   = statFiles(w.cfg.files)
+		signals chan os.Signal
+	)
+	if len(w.cfg.signals) > 0 {
+		signals = make(chan os.Signal, 1)
+		signal.Notify(signals, w.cfg.signals...)
+	}
+	config := new(T)
+	err := Load(config, w.cfg.loadOpts...)
+	if err != nil {
+		if signals != nil {
+			signal.Stop(signals)
+		}
+		return nil, err
+	}
+	w.current.Store(config)
+	go w.run(stats, signals)
+	return w, nil
+}
+
+// Config returns the latest loaded config, which must not be modified
+func (w *Watcher[T]) Config() *T {
+	return w.current.Load()
+}
+
+// Events returns the channel events are delivered on, which is closed once the context is done
+func (w *Watcher[T]) Events() <-chan Event[T] {
+	return w.events
+}
+
+// Reload loads the config again and swaps it in when it loads without errors, delivering an event when the config
+// changed or failed to load. The error from loading is returned.
+func (w *Watcher[T]) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var (
+		old    = w.current.Load()
+		config = new(T)
+		event  Event[T]
+	)
+	err := Load(config, w.cfg.loadOpts...)
+	if err != nil {
+		event = Event[T]{Old: old, Err: err}
+	} else {
+		event = Event[T]{Old: old, New: config, Changes: diff(reflect.ValueOf(old).Elem(), reflect.ValueOf(config).Elem(), nil)}
+		if len(event.Changes) == 0 {
+			return nil
+		}
+		w.current.Store(config)
+	}
+	w.deliver(event)
+	return err
+}
+
+// delivers an event without blocking, merging it with the event that was not received yet. Events are not delivered
+// once the context is done, since the channel is closed.
+func (w *Watcher[T]) deliver(event Event[T]) {
+	if w.ctx.Err() != nil {
+		return
+	}
+	select {
+	case pending := <-w.events:
+		var ok bool
+		event, ok = mergeEvents(pending, event)
+		if !ok {
+			return
+		}
+	default:
+	}
+	// reloads are serialized, so the channel is empty until this send
+	w.events <- event
+}
+
+// merges an event into one that was not received yet, diffing the oldest snapshot against the newest so the changes
+// of both are kept. A failed reload does not replace changes that were not received. Returns false when the config
+// is back to the snapshot before the pending event.
+func mergeEvents[T any](pending, event Event[T]) (Event[T], bool) {
+	if event.Err != nil {
+		if pending.Err == nil {
+			return pending, true
+		}
+		return event, true
+	}
+	changes := diff(reflect.ValueOf(pending.Old).Elem(), reflect.ValueOf(event.New).Elem(), nil)
+	if len(changes) == 0 {
+		return Event[T]{}, false
+	}
+	return Event[T]{Old: pending.Old, New: event.New, Changes: changes}, true
+}
+
+// reloads on every trigger until the context is done, starting from the stats of the watched files at the first load
+func (w *Watcher[T]) run(stats []fileStat, signals chan os.Signal) {
+	defer func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		close(w.events)
+	}()
+	if signals != nil {
+		defer signal.Stop(signals)
+	}
+	var (
+		interval <-chan time.Time
+		poll     <-chan time.Time
+	)
+	if w.cfg.interval > 0 {
+		ticker := time.NewTicker(w.cfg.interval)
+		defer ticker.Stop()
+		interval = ticker.C
+	}
+	if len(w.cfg.files) > 0 {
+		ticker := time.NewTicker(w.cfg.pollInterval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-interval:
+		case <-signals:
+		case <-poll:
+			latest := statFiles(w.cfg.files)
+			if reflect.DeepEqual(stats, latest) {
+				continue
+			}
+			stats = latest
+		}
+		// errors are delivered as events
+		_ = w.Reload()
+	}
+}
+
+// fileStat is the part of a file's info that shows it changed
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+// returns the stats of the files, files that cannot be read have an empty stat
+func statFiles(paths []string) []fileStat {
+	stats := make([]fileStat, len(paths))
+	for i, path := range paths {
+		info, err := os.Stat(path)
+		if err == nil {
+			stats[i] = fileStat{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return stats
+}
+
+// lists the fields that differ between two values of the same type, walking into nested structs and pointers to
+// structs. Unexported fields are skipped, the fields of embedded structs are listed as promoted fields and the values of
+// fields with a secret tag are redacted.
+func diff(before, after reflect.Value, path []string) []Change {
+	t := before.Type()
+	if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct && !isDecodable(t.Elem()) && !before.IsNil() && !after.IsNil() {
+		return diff(before.Elem(), after.Elem(), path)
+	}
+	if t.Kind() != reflect.Struct || isDecodable(t) {
+		// values read through unexported embedded fields that are not structs cannot be compared
+		if !before.CanInterface() {
+			return nil
+		}
+		if reflect.DeepEqual(before.Interface(), after.Interface()) {
+			return nil
+		}
+		return []Change{{Path: strings.Join(path, "."), Old: before.Interface(), New: after.Interface()}}
+	}
+	var changes []Change
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		// the fields of unexported embedded structs are promoted, so they are diffed through the embedded struct
+		if !structField.IsExported() && !structField.Anonymous {
+			continue
+		}
+		fieldPath := append(path[:len(path):len(path)], structField.Name)
+		if structField.Anonymous {
+			fieldPath = path
+		}
+		fieldChanges := diff(before.Field(i), after.Field(i), fieldPath)
+		if isSecretField(structField) {
+			for j := range fieldChanges {
+				fieldChanges[j].Old, fieldChanges[j].New = redacted, redacted
+			}
+		}
+		changes = append(changes, fieldChanges...)
+	}
+	return changes
+}
@@ -0,0 +1,204 @@
+package environ_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/NeedMoreVolume/environ"
+)
+
+type exampleWatchBase struct {
+	Token string `env:"WATCH_TEST_TOKEN" secret:"true"`
+}
+
+type exampleWatchConfig struct {
+	exampleWatchBase
+	Host     string `env:"WATCH_TEST_HOST" config:"host"`
+	Port     int    `env:"WATCH_TEST_PORT" default:"8080"`
+	Database struct {
+		Name string `env:"WATCH_TEST_DB_NAME"`
+	}
+}
+
+// waits for the next event from a watcher
+func nextEvent[T any](t *testing.T, w *environ.Watcher[T]) environ.Event[T] {
+	select {
+	case event := <-w.Events():
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an event")
+	}
+	return environ.Event[T]{}
+}
+
+func TestWatcherReload(t *testing.T) {
+	t.Setenv("WATCH_TEST_HOST", "before")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w, err := environ.Watch[exampleWatchConfig](ctx)
+	if err != nil {
+		slog.Error("failed to watch config", "error", err)
+		t.FailNow()
+	}
+	first := w.Config()
+	if first.Host != "before" || first.Port != 8080 {
+		slog.Error("config was not loaded", "result", first)
+		t.Fail()
+	}
+
+	t.Setenv("WATCH_TEST_HOST", "after")
+	t.Setenv("WATCH_TEST_DB_NAME", "app")
+	t.Setenv("WATCH_TEST_TOKEN", "rotated")
+	go func() {
+		_ = w.Reload()
+	}()
+	event := nextEvent(t, w)
+	// fields of unexported embedded structs are promoted and secrets are redacted
+	expected := []environ.Change{
+		{Path: "Token", Old: "[REDACTED]", New: "[REDACTED]"},
+		{Path: "Host", Old: "before", New: "after"},
+		{Path: "Database.Name", Old: "", New: "app"},
+	}
+	if event.Err != nil || event.Old != first || event.New != w.Config() || !reflect.DeepEqual(event.Changes, expected) {
+		slog.Error("unexpected event", "event", event, "expected changes", expected)
+		t.Fail()
+	}
+	// the previous snapshot is never modified
+	if first.Host != "before" {
+		slog.Error("previous snapshot was modified", "result", first)
+		t.Fail()
+	}
+
+	// a reload that fails keeps the current snapshot
+	t.Setenv("WATCH_TEST_PORT", "not a number")
+	current := w.Config()
+	go func() {
+		_ = w.Reload()
+	}()
+	event = nextEvent(t, w)
+	if !errors.Is(event.Err, environ.ErrInvalidFormat) || event.New != nil || w.Config() != current {
+		slog.Error("failed reload replaced the config", "event", event)
+		t.Fail()
+	}
+
+	// the events channel is closed when the context is done
+	cancel()
+	select {
+	case _, ok := <-w.Events():
+		if ok {
+			slog.Error("expected the events channel to be closed")
+			t.Fail()
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the events channel to close")
+	}
+}
+
+func TestWatcherReloadWithoutEvents(t *testing.T) {
+	t.Setenv("WATCH_TEST_HOST", "first")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w, err := environ.Watch[exampleWatchConfig](ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// reloads do not wait for events to be received, so callers can only read Config
+	for _, host := range []string{"second", "third"} {
+		t.Setenv("WATCH_TEST_HOST", host)
+		done := make(chan error, 1)
+		go func() {
+			done <- w.Reload()
+		}()
+		select {
+		case err = <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the reload")
+		}
+		if err != nil || w.Config().Host != host {
+			slog.Error("reload did not apply the change", "error", err, "result", w.Config())
+			t.Fail()
+		}
+	}
+	// events that were not received are merged, so no change is lost
+	event := nextEvent(t, w)
+	expected := []environ.Change{{Path: "Host", Old: "first", New: "third"}}
+	if event.Old.Host != "first" || event.New != w.Config() || !reflect.DeepEqual(event.Changes, expected) {
+		slog.Error("unexpected merged event", "event", event, "expected changes", expected)
+		t.Fail()
+	}
+
+	// a field that only changed in an event that was not received is still listed
+	t.Setenv("WATCH_TEST_DB_NAME", "app")
+	_ = w.Reload()
+	t.Setenv("WATCH_TEST_HOST", "fourth")
+	_ = w.Reload()
+	event = nextEvent(t, w)
+	expected = []environ.Change{
+		{Path: "Host", Old: "third", New: "fourth"},
+		{Path: "Database.Name", Old: "", New: "app"},
+	}
+	if !reflect.DeepEqual(event.Changes, expected) {
+		slog.Error("unexpected merged event", "event", event, "expected changes", expected)
+		t.Fail()
+	}
+}
+
+func TestWatcherTriggers(t *testing.T) {
+	t.Run("interval", func(t *testing.T) {
+		t.Setenv("WATCH_TEST_HOST", "before")
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		w, err := environ.Watch[exampleWatchConfig](ctx, environ.WatchInterval(10*time.Millisecond))
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Setenv("WATCH_TEST_HOST", "after")
+		event := nextEvent(t, w)
+		if event.New == nil || event.New.Host != "after" {
+			slog.Error("interval did not reload the config", "event", event)
+			t.Fail()
+		}
+	})
+
+	t.Run("files", func(t *testing.T) {
+		path := writeConfigFile(t, "config.json", `{"host": "before"}`)
+		source, err := environ.NewJSONFileSource(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		w, err := environ.Watch[exampleWatchConfig](ctx,
+			environ.WatchLoadOptions(environ.WithSources(source)),
+			environ.WatchFiles(path),
+			environ.WatchPollInterval(10*time.Millisecond),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(path, []byte(`{"host": "after the file changed"}`), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		event := nextEvent(t, w)
+		if event.New == nil || event.New.Host != "after the file changed" {
+			slog.Error("file change did not reload the config", "event", event)
+			t.Fail()
+		}
+	})
+
+}
+
+func TestWatchErrors(t *testing.T) {
+	t.Setenv("WATCH_TEST_PORT", "not a number")
+	_, err := environ.Watch[exampleWatchConfig](context.Background())
+	if !errors.Is(err, environ.ErrInvalidFormat) {
+		slog.Error("expected the first load to fail", "error", err)
+		t.Fail()
+	}
+}
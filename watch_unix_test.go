@@ -0,0 +1,33 @@
+//go:build unix
+
+package environ_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/NeedMoreVolume/environ"
+)
+
+func TestWatcherSignals(t *testing.T) {
+	t.Setenv("WATCH_TEST_HOST", "before")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w, err := environ.Watch[exampleWatchConfig](ctx, environ.WatchSignals(syscall.SIGHUP))
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("WATCH_TEST_HOST", "after")
+	err = syscall.Kill(os.Getpid(), syscall.SIGHUP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := nextEvent(t, w)
+	if event.New == nil || event.New.Host != "after" {
+		slog.Error("signal did not reload the config", "event", event)
+		t.Fail()
+	}
+}